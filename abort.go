@@ -0,0 +1,26 @@
+package simple_context
+
+// DefaultAbortStatus is the status code sent for the aborted requests that have not written a
+// status code when the handler chain finishes. Zero means no status code is written for them, and
+// the core implementation decides the response.
+var DefaultAbortStatus = 0
+
+// AbortWithStatus writes the status code and aborts the context. The status code written by it
+// takes precedence over DefaultAbortStatus.
+func (ctx *Context) AbortWithStatus(code int) error {
+	ctx.Abort()
+	return ctx.Status(code)
+}
+
+// StatusCode returns the status code written to the response, or zero if it's not written yet.
+func (ctx *Context) StatusCode() int {
+	return ctx.status
+}
+
+// applyAbortStatus writes DefaultAbortStatus if the context is aborted without writing a status
+// code. The default 200 OK status of the buffered body doesn't count as written.
+func (ctx *Context) applyAbortStatus() {
+	if ctx.isAbort && !ctx.statusWritten && DefaultAbortStatus != 0 {
+		_ = ctx.Status(DefaultAbortStatus)
+	}
+}
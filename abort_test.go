@@ -0,0 +1,237 @@
+package simple_context
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// testCore is a minimal core context for the tests, the methods not implemented by it panic.
+type testCore struct {
+	contextImpl
+	req *testRequest
+	res *testResponse
+}
+
+func newTestCore(method, path string) *testCore {
+	return &testCore{
+		req: &testRequest{method: method, path: path, header: http.Header{}, query: url.Values{}},
+		res: &testResponse{header: http.Header{}},
+	}
+}
+
+func (c *testCore) Context() context.Context         { return context.Background() }
+func (c *testCore) Request() core.Request            { return c.req }
+func (c *testCore) Response() core.Response          { return c.res }
+func (c *testCore) Method() string                   { return c.req.Method() }
+func (c *testCore) Path() string                     { return c.req.Path() }
+func (c *testCore) Resource() string                 { return c.req.Resource() }
+func (c *testCore) Header(key string) string         { return c.req.Header(key) }
+func (c *testCore) Headers() http.Header             { return c.req.Headers() }
+func (c *testCore) ContentLength() int64             { return c.req.ContentLength() }
+func (c *testCore) GetHeader(key string) string      { return c.res.GetHeader(key) }
+func (c *testCore) SetHeader(key, value string)      { c.res.SetHeader(key, value) }
+func (c *testCore) AddHeader(key, value string)      { c.res.AddHeader(key, value) }
+func (c *testCore) DelHeader(key string)             { c.res.DelHeader(key) }
+func (c *testCore) Status(code int) error            { return c.res.Status(code) }
+func (c *testCore) Write(data []byte) (int, error)   { return c.res.Write(data) }
+func (c *testCore) HeaderValues(key string) []string { return c.req.HeaderValues(key) }
+
+// testRequest is the request of testCore.
+type testRequest struct {
+	core.Request
+	method   string
+	path     string
+	resource string
+	header   http.Header
+	query    url.Values
+	body     []byte
+	// raw is the net/http request exposed by RawRequest, for the tests of the streamed bodies.
+	raw *http.Request
+}
+
+func (r *testRequest) Method() string                   { return r.method }
+func (r *testRequest) Path() string                     { return r.path }
+func (r *testRequest) Resource() string                 { return r.resource }
+func (r *testRequest) Header(key string) string         { return r.header.Get(key) }
+func (r *testRequest) HeaderValues(key string) []string { return r.header.Values(key) }
+func (r *testRequest) Headers() http.Header             { return r.header }
+func (r *testRequest) ContentLength() int64             { return int64(len(r.body)) }
+func (r *testRequest) Body() ([]byte, error)            { return r.body, nil }
+func (r *testRequest) Queries() url.Values              { return r.query }
+func (r *testRequest) ClientIP() string                 { return "192.0.2.1" }
+func (r *testRequest) RawRequest() *http.Request        { return r.raw }
+
+func (r *testRequest) Cookie(name string) (*http.Cookie, error) {
+	return (&http.Request{Header: r.header}).Cookie(name)
+}
+
+func (r *testRequest) Cookies() []*http.Cookie {
+	return (&http.Request{Header: r.header}).Cookies()
+}
+
+// testResponse records the response of testCore.
+type testResponse struct {
+	header  http.Header
+	status  int
+	body    []byte
+	flushes int
+}
+
+func (r *testResponse) AddHeader(key, value string) { r.header.Add(key, value) }
+func (r *testResponse) SetHeader(key, value string) { r.header.Set(key, value) }
+func (r *testResponse) GetHeader(key string) string { return r.header.Get(key) }
+func (r *testResponse) DelHeader(key string)        { r.header.Del(key) }
+func (r *testResponse) Flush()                      { r.flushes++ }
+
+func (r *testResponse) Status(code int) error {
+	r.status = code
+	return nil
+}
+
+func (r *testResponse) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body = append(r.body, data...)
+	return len(data), nil
+}
+
+// runTestContext runs the handlers with a new context, and returns the recorded response.
+func runTestContext(t *testing.T, handlers ...core.HandlerFunc) *testResponse {
+	t.Helper()
+
+	return runTestCore(t, newTestCore(http.MethodGet, "/"), handlers...)
+}
+
+// runTestCore runs the handlers with a new context of the core context, and returns the recorded
+// response.
+func runTestCore(t *testing.T, impl *testCore, handlers ...core.HandlerFunc) *testResponse {
+	t.Helper()
+
+	ctx := new(Context)
+	InitContext(ctx, impl)
+	ctx.Use(handlers...)
+	ctx.Next()
+
+	return impl.res
+}
+
+// setTestValue sets the variable to the value for the test, and restores it after the test.
+func setTestValue[T any](t *testing.T, p *T, value T) {
+	t.Helper()
+
+	old := *p
+	*p = value
+	t.Cleanup(func() {
+		*p = old
+	})
+}
+
+// testCookieHeader returns the Cookie header sending back the cookies set by the response.
+func testCookieHeader(res *testResponse) string {
+	cookies := (&http.Response{Header: res.header}).Cookies()
+
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+	}
+
+	return strings.Join(pairs, "; ")
+}
+
+func setDefaultAbortStatus(t *testing.T, code int) {
+	t.Helper()
+
+	old := DefaultAbortStatus
+	DefaultAbortStatus = code
+	t.Cleanup(func() {
+		DefaultAbortStatus = old
+	})
+}
+
+func TestAbortWritesDefaultStatus(t *testing.T) {
+	setDefaultAbortStatus(t, http.StatusForbidden)
+
+	called := false
+	res := runTestContext(t, func(c core.Context) {
+		c.Abort()
+	}, func(c core.Context) {
+		called = true
+	})
+
+	if called {
+		t.Error("the handler after Abort is called")
+	}
+	if res.status != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", res.status, http.StatusForbidden)
+	}
+}
+
+func TestAbortWithoutDefaultStatus(t *testing.T) {
+	setDefaultAbortStatus(t, 0)
+
+	res := runTestContext(t, func(c core.Context) {
+		c.Abort()
+	})
+
+	if res.status != 0 {
+		t.Errorf("status = %d, want no status written", res.status)
+	}
+}
+
+func TestAbortWithStatus(t *testing.T) {
+	setDefaultAbortStatus(t, http.StatusForbidden)
+
+	res := runTestContext(t, func(c core.Context) {
+		if err := c.(*Context).AbortWithStatus(http.StatusTeapot); err != nil {
+			t.Errorf("AbortWithStatus() error = %v", err)
+		}
+	})
+
+	if res.status != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", res.status, http.StatusTeapot)
+	}
+}
+
+func TestAbortAfterBufferedWrite(t *testing.T) {
+	setDefaultAbortStatus(t, http.StatusForbidden)
+
+	res := runTestContext(t, func(c core.Context) {
+		ctx := c.(*Context)
+		if err := ctx.BufferResponse(); err != nil {
+			t.Fatalf("BufferResponse() error = %v", err)
+		}
+		if _, err := ctx.Write([]byte("partial")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		ctx.Abort()
+	})
+
+	if res.status != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", res.status, http.StatusForbidden)
+	}
+	if string(res.body) != "partial" {
+		t.Errorf("body = %q, want %q", res.body, "partial")
+	}
+}
+
+func TestAbortAfterUnbufferedWrite(t *testing.T) {
+	setDefaultAbortStatus(t, http.StatusForbidden)
+
+	res := runTestContext(t, func(c core.Context) {
+		ctx := c.(*Context)
+		if _, err := ctx.Write([]byte("sent")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		ctx.Abort()
+	})
+
+	if res.status != http.StatusOK {
+		t.Errorf("status = %d, want %d", res.status, http.StatusOK)
+	}
+}
@@ -0,0 +1,72 @@
+package simple_context
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// AdmissionFunc decides whether a request is admitted before its handler chain runs. It returns
+// false with the duration the client should retry after to reject the request.
+type AdmissionFunc func(ctx *Context) (retryAfter time.Duration, ok bool)
+
+var admission atomic.Pointer[AdmissionFunc]
+
+// SetAdmissionControl sets the admission hook that is invoked before the first handler of every
+// request. The rejected requests are responded with 503 Service Unavailable and the Retry-After
+// header, and their handler chains are not executed. Passing nil removes the hook.
+func SetAdmissionControl(f AdmissionFunc) {
+	if f == nil {
+		admission.Store(nil)
+		return
+	}
+	admission.Store(&f)
+}
+
+// GoroutineLimit returns an admission hook that rejects the requests while the count of
+// goroutines exceeds the limit.
+func GoroutineLimit(limit int, retryAfter time.Duration) AdmissionFunc {
+	return func(*Context) (time.Duration, bool) {
+		if runtime.NumGoroutine() > limit {
+			return retryAfter, false
+		}
+		return 0, true
+	}
+}
+
+// HeapLimit returns an admission hook that rejects the requests while the bytes of the live heap
+// objects exceed the limit.
+func HeapLimit(limit uint64, retryAfter time.Duration) AdmissionFunc {
+	return func(*Context) (time.Duration, bool) {
+		sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+		metrics.Read(sample)
+		if sample[0].Value.Kind() == metrics.KindUint64 && sample[0].Value.Uint64() > limit {
+			return retryAfter, false
+		}
+		return 0, true
+	}
+}
+
+// admit runs the admission hook, and responds with 503 Service Unavailable and aborts the context
+// if the request is rejected.
+func (ctx *Context) admit() bool {
+	f := admission.Load()
+	if f == nil {
+		return true
+	}
+
+	retryAfter, ok := (*f)(ctx)
+	if ok {
+		return true
+	}
+
+	if retryAfter > 0 {
+		ctx.RetryAfter(retryAfter)
+	}
+	ctx.Abort()
+	_ = ctx.Status(http.StatusServiceUnavailable)
+
+	return false
+}
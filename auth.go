@@ -0,0 +1,193 @@
+package simple_context
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BearerTokenConfig is the configuration of the fallback sources of the bearer tokens.
+type BearerTokenConfig struct {
+	// QueryParam is the name of the query parameter carrying the token if the request has no
+	// bearer Authorization header. Empty disables the query fallback.
+	QueryParam string
+	// Cookie is the name of the cookie carrying the token if the request has no bearer
+	// Authorization header or query parameter. Empty disables the cookie fallback.
+	Cookie string
+}
+
+// BearerTokens is the bearer token configuration of the contexts. The fallbacks are disabled by
+// default, as the tokens in the URLs may leak through the logs and the Referer headers.
+var BearerTokens = BearerTokenConfig{}
+
+// BearerToken returns the token of the Bearer Authorization header of the request, or the token in
+// the fallback query parameter or cookie of BearerTokens. The scheme is matched case-insensitively
+// and the surrounding whitespace is ignored.
+func (ctx *Context) BearerToken() (string, bool) {
+	if token, ok := parseAuthorization(ctx.Header("Authorization"), "Bearer"); ok {
+		return token, true
+	}
+
+	config := BearerTokens
+	if config.QueryParam != "" {
+		if token := strings.TrimSpace(ctx.Query(config.QueryParam)); token != "" {
+			return token, true
+		}
+	}
+	if config.Cookie != "" {
+		if cookie, err := ctx.Cookie(config.Cookie); err == nil {
+			if token := strings.TrimSpace(cookie.Value); token != "" {
+				return token, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// parseAuthorization returns the credentials of the Authorization header value if its scheme is
+// the scheme, matched case-insensitively.
+func parseAuthorization(header, scheme string) (string, bool) {
+	header = strings.TrimSpace(header)
+	if len(header) <= len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return "", false
+	}
+
+	rest := header[len(scheme):]
+	if rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+	credentials := strings.TrimSpace(rest)
+	if credentials == "" {
+		return "", false
+	}
+
+	return credentials, true
+}
+
+// APIKeySource is a source of the API keys of the requests.
+type APIKeySource func(ctx *Context) (string, bool)
+
+// APIKeyHeader returns the source of the API keys in the request header.
+func APIKeyHeader(name string) APIKeySource {
+	return func(ctx *Context) (string, bool) {
+		key := strings.TrimSpace(ctx.Header(name))
+		return key, key != ""
+	}
+}
+
+// APIKeyQuery returns the source of the API keys in the query parameter.
+func APIKeyQuery(name string) APIKeySource {
+	return func(ctx *Context) (string, bool) {
+		key := strings.TrimSpace(ctx.Query(name))
+		return key, key != ""
+	}
+}
+
+// APIKeyCookie returns the source of the API keys in the cookie.
+func APIKeyCookie(name string) APIKeySource {
+	return func(ctx *Context) (string, bool) {
+		cookie, err := ctx.Cookie(name)
+		if err != nil {
+			return "", false
+		}
+		key := strings.TrimSpace(cookie.Value)
+		return key, key != ""
+	}
+}
+
+// DefaultAPIKeySources are the sources of the API keys used by APIKey if no source is specified.
+var DefaultAPIKeySources = []APIKeySource{APIKeyHeader("X-API-Key")}
+
+// APIKey returns the API key of the request from the first source that has one, the sources are
+// DefaultAPIKeySources if none is specified.
+func (ctx *Context) APIKey(opts ...APIKeySource) (string, bool) {
+	if len(opts) == 0 {
+		opts = DefaultAPIKeySources
+	}
+
+	for _, source := range opts {
+		if key, ok := source(ctx); ok {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// RequireBasicAuth checks the Basic Authorization credentials of the request by the check function.
+// It responds with 401 Unauthorized and the Basic WWW-Authenticate challenge of the realm, and
+// aborts the context if the credentials are missing or rejected. The check function should compare
+// the credentials in constant time, like the one returned by BasicAuthAccounts.
+func (ctx *Context) RequireBasicAuth(realm string, check func(user, pass string) bool) bool {
+	if user, pass, ok := ctx.BasicAuth(); ok && check(user, pass) {
+		return true
+	}
+
+	ctx.SetHeader("WWW-Authenticate", "Basic realm="+strconv.Quote(realm)+`, charset="UTF-8"`)
+	ctx.Abort()
+	_ = ctx.Status(http.StatusUnauthorized)
+
+	return false
+}
+
+// BasicAuthAccounts returns the check function of RequireBasicAuth for the accounts, a map of the
+// usernames to the passwords. The credentials are compared in constant time, so the response time
+// doesn't leak which part of them is wrong or how long the passwords are.
+func BasicAuthAccounts(accounts map[string]string) func(user, pass string) bool {
+	hashed := make(map[[sha256.Size]byte][sha256.Size]byte, len(accounts))
+	for user, pass := range accounts {
+		hashed[sha256.Sum256([]byte(user))] = sha256.Sum256([]byte(pass))
+	}
+
+	return func(user, pass string) bool {
+		userHash := sha256.Sum256([]byte(user))
+		passHash := sha256.Sum256([]byte(pass))
+
+		matched := 0
+		for u, p := range hashed {
+			userMatched := subtle.ConstantTimeCompare(userHash[:], u[:])
+			passMatched := subtle.ConstantTimeCompare(passHash[:], p[:])
+			matched |= userMatched & passMatched
+		}
+
+		return matched == 1
+	}
+}
+
+// ProxyBasicAuth returns the username and password from the Basic Proxy-Authorization header if
+// present, for the services acting as forward proxies.
+func (ctx *Context) ProxyBasicAuth() (string, string, bool) {
+	credentials, ok := parseAuthorization(ctx.Header("Proxy-Authorization"), "Basic")
+	if !ok {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return "", "", false
+	}
+
+	return strings.Cut(string(decoded), ":")
+}
+
+// AuthorizationScheme returns the scheme and the parameters of the Authorization header of the
+// request, like "Signature" and its comma-separated parameters, for handling the custom schemes.
+// The parameters are returned as is with the surrounding whitespace trimmed, and they can be
+// parsed by ParseAuthParams.
+func (ctx *Context) AuthorizationScheme() (scheme, params string) {
+	header := strings.TrimSpace(ctx.Header("Authorization"))
+	scheme, params, _ = strings.Cut(header, " ")
+
+	return scheme, strings.TrimSpace(params)
+}
+
+// ParseAuthParams parses the comma-separated auth parameters of an Authorization header, like
+// `keyId="key", signature="..."`. The values may be tokens or quoted strings, and the parameter
+// names are lowercased.
+func ParseAuthParams(params string) map[string]string {
+	return parseAuthParams(params)
+}
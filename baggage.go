@@ -0,0 +1,101 @@
+package simple_context
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxBaggageMembers is the maximum count of the baggage members by the W3C Baggage spec.
+	maxBaggageMembers = 180
+	// maxBaggageBytes is the maximum bytes of the baggage header by the W3C Baggage spec.
+	maxBaggageBytes = 8192
+)
+
+// baggageKey is the key of the baggage in the standard context.
+type baggageKey struct{}
+
+// ParseBaggage parses the W3C baggage header value into the members. The properties of the
+// members are dropped, and the malformed members are skipped.
+func ParseBaggage(value string) map[string]string {
+	members := make(map[string]string)
+	if len(value) > maxBaggageBytes {
+		return members
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		member, _, _ := strings.Cut(item, ";")
+		key, val, ok := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t\"(),/:;<=>?@[\\]{}") {
+			continue
+		}
+
+		decoded, err := url.PathUnescape(strings.TrimSpace(val))
+		if err != nil {
+			continue
+		}
+		members[key] = decoded
+		if len(members) >= maxBaggageMembers {
+			break
+		}
+	}
+
+	return members
+}
+
+// FormatBaggage formats the members into the W3C baggage header value, ordered by the keys.
+func FormatBaggage(members map[string]string) string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, key+"="+url.PathEscape(members[key]))
+	}
+
+	return strings.Join(items, ",")
+}
+
+// BaggageFromContext returns the baggage members set to the standard context by SetBaggage.
+func BaggageFromContext(stdCtx context.Context) (map[string]string, bool) {
+	members, ok := stdCtx.Value(baggageKey{}).(map[string]string)
+	return members, ok
+}
+
+// Baggage returns the baggage members of the request, the members in the baggage header with the
+// ones added by SetBaggage. The returned map must not be modified.
+func (ctx *Context) Baggage() map[string]string {
+	if members, ok := BaggageFromContext(ctx.StdContext()); ok {
+		return members
+	}
+
+	return ParseBaggage(strings.Join(ctx.HeaderValues("baggage"), ","))
+}
+
+// SetBaggage adds the baggage member to the standard context of the request, so it flows to the
+// downstream calls by InjectBaggage or BaggageFromContext.
+func (ctx *Context) SetBaggage(key, value string) {
+	members := maps.Clone(ctx.Baggage())
+	if members == nil {
+		members = make(map[string]string)
+	}
+	members[key] = value
+
+	ctx.SetStdContext(context.WithValue(ctx.StdContext(), baggageKey{}, members))
+}
+
+// InjectBaggage sets the baggage header of the outbound request to the baggage members of the
+// request.
+func (ctx *Context) InjectBaggage(header http.Header) {
+	if members := ctx.Baggage(); len(members) > 0 {
+		header.Set("baggage", FormatBaggage(members))
+	}
+}
@@ -0,0 +1,50 @@
+package simple_context
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// BindJSON decodes the JSON request body into the value. The body is transcoded to UTF-8 from the
+// charset of the request.
+func (ctx *Context) BindJSON(v any) error {
+	body, err := ctx.utf8Body()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// BindXML decodes the XML request body into the value. The documents declaring a non-UTF-8
+// encoding are transcoded by the registered charset decoders.
+func (ctx *Context) BindXML(v any) error {
+	body, err := ctx.Body()
+	if err != nil {
+		return err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		charsetDecoder, ok := charsetDecoders[strings.ToLower(charset)]
+		if !ok {
+			return nil, ErrUnsupportedCharset
+		}
+
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		data, err = charsetDecoder(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return bytes.NewReader(data), nil
+	}
+
+	return decoder.Decode(v)
+}
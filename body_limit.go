@@ -0,0 +1,48 @@
+package simple_context
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrBodyTooLarge is returned by Body if the request body exceeds the body limit of the context.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// DefaultBodyLimit is the maximum bytes of the request bodies of the contexts before the content
+// coding is decoded. Zero means unlimited.
+var DefaultBodyLimit int64 = 0
+
+// LimitBody sets the maximum bytes of the request body of the current request, it overrides
+// DefaultBodyLimit. Reading a body over the limit by Body responds with 413 Content Too Large and
+// aborts the context. If the core implementation exposes the net/http request, the reading stops
+// at the limit and the body is never fully buffered. Otherwise, the body is buffered by the core
+// implementation before the limit applies, and only the bodies with a Content-Length over the
+// limit are rejected without reading them. It must be called before the body is read.
+func (ctx *Context) LimitBody(maxBytes int64) {
+	ctx.bodyLimit = maxBytes
+}
+
+// limitRawBody wraps the body of the net/http request by the body limit, so the core
+// implementation stops reading it at the limit.
+func (ctx *Context) limitRawBody() {
+	if ctx.bodyLimit <= 0 {
+		return
+	}
+
+	if raw := ctx.RawRequest(); raw != nil && raw.Body != nil {
+		raw.Body = http.MaxBytesReader(ctx.RawResponseWriter(), raw.Body, ctx.bodyLimit)
+	}
+}
+
+// checkBodyLimit checks the length or the read error of the request body against the body limit.
+// It responds with 413 Content Too Large and aborts the context if the body exceeds the limit.
+func (ctx *Context) checkBodyLimit(length int64, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || (ctx.bodyLimit > 0 && length > ctx.bodyLimit) {
+		ctx.Abort()
+		_ = ctx.Status(http.StatusRequestEntityTooLarge)
+		return ErrBodyTooLarge
+	}
+
+	return err
+}
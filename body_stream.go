@@ -0,0 +1,206 @@
+package simple_context
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrBodyConsumed is returned by Body if the request body has been consumed as a stream.
+var ErrBodyConsumed = errors.New("request body consumed as a stream")
+
+// bodyStream returns the reader of the decoded request body and the function closing it. It reads
+// the cached body if it has been read, or streams the body of the net/http request without
+// buffering it. The streamed body can't be read by Body anymore.
+func (ctx *Context) bodyStream() (io.Reader, func(), error) {
+	if ctx.bodyRead {
+		return bytes.NewReader(ctx.body), func() {}, nil
+	}
+	if ctx.bodyConsumed {
+		return nil, nil, ErrBodyConsumed
+	}
+	if source := ctx.bodySource; source != nil {
+		ctx.bodySource = nil
+		ctx.bodyConsumed = true
+		return ctx.teeBody(source), func() {
+			if closer, ok := source.(io.Closer); ok {
+				closer.Close()
+			}
+		}, nil
+	}
+
+	raw := ctx.RawRequest()
+	if raw == nil || raw.Body == nil {
+		body, err := ctx.Body()
+		if err != nil {
+			return nil, nil, err
+		}
+		return bytes.NewReader(body), func() {}, nil
+	}
+
+	if err := ctx.checkBodyLimit(ctx.ContentLength(), nil); err != nil {
+		return nil, nil, err
+	}
+	ctx.limitRawBody()
+
+	reader, closeDecoders, err := decodeStream(ctx.teeBody(raw.Body), ctx.contentCodings())
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx.bodyConsumed = true
+
+	return reader, closeDecoders, nil
+}
+
+// RawData returns the request body as received from the client, before the content coding is
+// decoded, for verifying the signatures and the digests over the raw bytes. It's the same as Body
+// if the body is not encoded.
+func (ctx *Context) RawData() ([]byte, error) {
+	if _, err := ctx.Body(); err != nil {
+		return nil, err
+	}
+
+	return ctx.encodedBody, nil
+}
+
+// ResetBody rewinds the request body, so the next reader reads it from the beginning. It buffers
+// the body if it's not read yet, and returns ErrBodyConsumed if the body has been streamed without
+// buffering.
+func (ctx *Context) ResetBody() error {
+	_, err := ctx.Body()
+	return err
+}
+
+// SetBody replaces the request body with the data of the reader, for the middlewares that decrypt
+// or rewrite the payloads. The reader is read by the next reader of the body, like Body or Bind,
+// and closed after it's read if it's an io.Closer. The replaced body is never decoded by the
+// Content-Encoding header.
+func (ctx *Context) SetBody(r io.Reader) {
+	ctx.replaceBody(nil)
+	ctx.bodySource = r
+	ctx.bodyRead = false
+}
+
+// SetBodyBytes replaces the request body with the data, for the middlewares that decrypt or
+// rewrite the payloads. The replaced body is never decoded by the Content-Encoding header. It
+// returns the error of the memory budget if the data exceeds it, and the body can't be read after
+// that.
+func (ctx *Context) SetBodyBytes(data []byte) error {
+	ctx.replaceBody(nil)
+	if err := ctx.reserveMemory(int64(len(data))); err != nil {
+		ctx.bodyRead = false
+		ctx.bodyConsumed = true
+		return err
+	}
+	ctx.replaceBody(data)
+
+	return nil
+}
+
+// replaceBody replaces the cached body with the data, and drops the caches derived from the
+// previous body.
+func (ctx *Context) replaceBody(data []byte) {
+	if ctx.bodyRead {
+		ctx.releaseMemory(int64(len(ctx.body)))
+	}
+	if closer, ok := ctx.bodySource.(io.Closer); ok {
+		closer.Close()
+	}
+
+	ctx.body = data
+	ctx.encodedBody = data
+	ctx.bodyRead = true
+	ctx.bodyConsumed = false
+	ctx.bodyReplaced = true
+	ctx.bodySource = nil
+	ctx.multipartForm = nil
+	ctx.postForm = nil
+}
+
+// readBodySource reads and caches the body replaced by SetBody.
+func (ctx *Context) readBodySource() ([]byte, error) {
+	source := ctx.bodySource
+	data, err := io.ReadAll(ctx.teeBody(source))
+	if closer, ok := source.(io.Closer); ok {
+		closer.Close()
+	}
+	ctx.bodySource = nil
+	if err != nil {
+		ctx.bodyConsumed = true
+		return nil, err
+	}
+
+	if err := ctx.reserveMemory(int64(len(data))); err != nil {
+		ctx.bodyConsumed = true
+		return nil, err
+	}
+	ctx.body = data
+	ctx.encodedBody = data
+	ctx.bodyRead = true
+
+	return data, nil
+}
+
+// BodyReader returns the decoded request body as a stream, for piping large bodies to another
+// destination without buffering them. It reads the cached body if the body has been read, or
+// streams the body of the net/http request otherwise, then the body can't be read by Body anymore.
+// The errors of getting the body, like ErrBodyConsumed, are returned by the Read calls.
+func (ctx *Context) BodyReader() io.ReadCloser {
+	reader, closeBody, err := ctx.bodyStream()
+	if err != nil {
+		return &bodyReader{err: err}
+	}
+
+	return &bodyReader{r: reader, close: closeBody}
+}
+
+// bodyReader is the stream of the request body returned by BodyReader.
+type bodyReader struct {
+	r     io.Reader
+	close func()
+	err   error
+}
+
+// Read reads the request body.
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	return b.r.Read(p)
+}
+
+// Close closes the decoders of the request body.
+func (b *bodyReader) Close() error {
+	if b.close != nil {
+		b.close()
+		b.close = nil
+	}
+
+	return nil
+}
+
+// TeeBody copies the request body as received to the writer as it's consumed, for archiving the
+// payloads without reading them twice. The body is copied at once if it has been read, the
+// copying errors are returned by the readers of the body. It must not be called after the body is
+// streamed by BodyReader.
+func (ctx *Context) TeeBody(w io.Writer) {
+	if ctx.bodyRead {
+		_, _ = w.Write(ctx.encodedBody)
+		return
+	}
+
+	if ctx.bodyTee != nil {
+		w = io.MultiWriter(ctx.bodyTee, w)
+	}
+	ctx.bodyTee = w
+}
+
+// teeBody returns the reader copying the body to the tee writer as it's read.
+func (ctx *Context) teeBody(r io.Reader) io.Reader {
+	if ctx.bodyTee == nil {
+		return r
+	}
+
+	return io.TeeReader(r, ctx.bodyTee)
+}
@@ -0,0 +1,104 @@
+package simple_context
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BudgetAllocation is a part of the time budget of a request spent on a downstream call.
+type BudgetAllocation struct {
+	// Name is the name of the downstream dependency.
+	Name string
+	// Allocated is the time allocated to the call.
+	Allocated time.Duration
+	// Spent is the time the call took, it's zero until the call finishes.
+	Spent time.Duration
+	// TimedOut reports whether the call exceeded its allocation.
+	TimedOut bool
+}
+
+// Budget is the time budget of a request against its deadline.
+type Budget struct {
+	// Deadline is the deadline of the request, it's zero if the request has no deadline.
+	Deadline time.Time
+	// Remaining is the time remaining before the deadline, it's negative if the request has no
+	// deadline.
+	Remaining time.Duration
+	// Allocations are the parts of the budget spent by SpendBudget.
+	Allocations []BudgetAllocation
+}
+
+// Budget returns the time budget of the request against the deadline of its standard context.
+func (ctx *Context) Budget() Budget {
+	budget := Budget{
+		Remaining:   -1,
+		Allocations: append([]BudgetAllocation(nil), ctx.budgetAllocations...),
+	}
+
+	if deadline, ok := ctx.StdContext().Deadline(); ok {
+		budget.Deadline = deadline
+		budget.Remaining = deadline.Sub(ctx.Now())
+		if budget.Remaining < 0 {
+			budget.Remaining = 0
+		}
+	}
+
+	return budget
+}
+
+// SpendBudget allocates a part of the time budget to a downstream call of the name. It returns a
+// standard context with the timeout of the allocation, capped by the remaining budget, and a
+// function that must be called when the call finishes. The function records the time spent in
+// the Server-Timing header of the response.
+func (ctx *Context) SpendBudget(name string, d time.Duration) (context.Context, context.CancelFunc) {
+	if remaining := ctx.Budget().Remaining; remaining >= 0 && remaining < d {
+		d = remaining
+	}
+
+	stdCtx, cancel := context.WithTimeout(ctx.StdContext(), d)
+	ctx.budgetAllocations = append(ctx.budgetAllocations, BudgetAllocation{Name: name, Allocated: d})
+	index := len(ctx.budgetAllocations) - 1
+	start := ctx.Now()
+
+	done := false
+	return stdCtx, func() {
+		if done {
+			return
+		}
+		done = true
+
+		allocation := &ctx.budgetAllocations[index]
+		allocation.Spent = ctx.Now().Sub(start)
+		allocation.TimedOut = stdCtx.Err() == context.DeadlineExceeded
+		cancel()
+
+		desc := "budget " + strconv.FormatInt(allocation.Allocated.Milliseconds(), 10) + "ms"
+		if allocation.TimedOut {
+			desc += ", timed out"
+		}
+		ctx.AddServerTiming(name, allocation.Spent, desc)
+	}
+}
+
+// AddServerTiming adds a metric to the Server-Timing header of the response.
+func (ctx *Context) AddServerTiming(name string, d time.Duration, desc string) {
+	metric := serverTimingToken(name) + ";dur=" +
+		strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+	if desc != "" {
+		metric += ";desc=" + strconv.Quote(desc)
+	}
+
+	ctx.AddHeader("Server-Timing", metric)
+}
+
+// serverTimingToken replaces the characters that are not allowed in a token with underscores.
+func serverTimingToken(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,182 @@
+package simple_context
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+)
+
+// BufferConfig is the configuration of the response buffering.
+type BufferConfig struct {
+	// MemoryThreshold is the size in bytes that a buffered response is kept in memory up to, the
+	// response is spilled to a temporary file if it grows larger. The default is 1 MiB.
+	MemoryThreshold int64
+	// TempDir is the directory of the temporary files, the default is os.TempDir().
+	TempDir string
+}
+
+// ResponseBuffering is the response buffering configuration of the contexts.
+var ResponseBuffering = BufferConfig{}
+
+const defaultMemoryThreshold = 1 << 20
+
+// spillBuffer is a buffer that keeps the data in memory until it grows larger than the threshold,
+// and spills it to a temporary file after that.
+type spillBuffer struct {
+	ctx       *Context
+	mem       bytes.Buffer
+	file      *os.File
+	size      int64
+	threshold int64
+	dir       string
+}
+
+// newSpillBuffer creates a spill buffer for the context. The size hint is the expected size of
+// the data, the buffer spills to a temporary file immediately if it exceeds the threshold.
+func newSpillBuffer(ctx *Context, sizeHint int64) (*spillBuffer, error) {
+	config := ResponseBuffering
+	buf := &spillBuffer{
+		ctx:       ctx,
+		threshold: config.MemoryThreshold,
+		dir:       config.TempDir,
+	}
+	if buf.threshold <= 0 {
+		buf.threshold = defaultMemoryThreshold
+	}
+
+	if sizeHint > buf.threshold {
+		if err := buf.spill(); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+// Write writes the data into the buffer.
+func (buf *spillBuffer) Write(p []byte) (int, error) {
+	if buf.file == nil && int64(buf.mem.Len()+len(p)) > buf.threshold {
+		if err := buf.spill(); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int
+	var err error
+	if buf.file != nil {
+		n, err = buf.file.Write(p)
+	} else {
+		if err := buf.ctx.reserveMemory(int64(len(p))); err != nil {
+			return 0, err
+		}
+		n, err = buf.mem.Write(p)
+	}
+	buf.size += int64(n)
+
+	return n, err
+}
+
+// Len returns the size of the buffered data.
+func (buf *spillBuffer) Len() int64 {
+	return buf.size
+}
+
+// Bytes returns the buffered data, reading it back from the temporary file if it's spilled.
+func (buf *spillBuffer) Bytes() ([]byte, error) {
+	if buf.file == nil {
+		return buf.mem.Bytes(), nil
+	}
+
+	if _, err := buf.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(buf.file)
+}
+
+// WriteTo writes the buffered data to the writer.
+func (buf *spillBuffer) WriteTo(w io.Writer) (int64, error) {
+	if buf.file == nil {
+		return buf.mem.WriteTo(w)
+	}
+
+	if _, err := buf.file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.Copy(w, buf.file)
+}
+
+// Reset discards the buffered data.
+func (buf *spillBuffer) Reset() {
+	buf.Close()
+	buf.size = 0
+}
+
+// Close discards the buffered data and removes the temporary file.
+func (buf *spillBuffer) Close() error {
+	buf.ctx.releaseMemory(int64(buf.mem.Len()))
+	buf.mem.Reset()
+
+	if buf.file == nil {
+		return nil
+	}
+
+	name := buf.file.Name()
+	err := buf.file.Close()
+	buf.file = nil
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+
+	return err
+}
+
+// spill moves the buffered data into a temporary file.
+func (buf *spillBuffer) spill() error {
+	file, err := os.CreateTemp(buf.dir, "amwk-response-*")
+	if err != nil {
+		return err
+	}
+
+	buffered := int64(buf.mem.Len())
+	if _, err := buf.mem.WriteTo(file); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	buf.ctx.releaseMemory(buffered)
+	buf.mem = bytes.Buffer{}
+	buf.file = file
+
+	return nil
+}
+
+// BufferResponse enables buffering of the response body written by Write, so it can be inspected
+// or replaced before it's sent. The buffered body is kept in memory, or spilled to a temporary
+// file if the Content-Length header of the response or the observed size exceeds the memory
+// threshold, and it's sent when the handler chain finishes.
+func (ctx *Context) BufferResponse() error {
+	if ctx.resBuffer != nil {
+		return nil
+	}
+
+	sizeHint, _ := strconv.ParseInt(ctx.GetHeader("Content-Length"), 10, 64)
+	buf, err := newSpillBuffer(ctx, sizeHint)
+	if err != nil {
+		return err
+	}
+	ctx.resBuffer = buf
+
+	ctx.onFinish(func() {
+		if ctx.resBuffer == nil {
+			return
+		}
+		defer ctx.resBuffer.Close()
+
+		buf := ctx.resBuffer
+		ctx.resBuffer = nil
+		buf.WriteTo(responseWriter{ctx})
+	})
+
+	return nil
+}
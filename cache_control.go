@@ -0,0 +1,133 @@
+package simple_context
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControlBuilder builds the Cache-Control header of a response.
+type CacheControlBuilder struct {
+	ctx        *Context
+	directives []string
+}
+
+// CacheControl returns a builder of the Cache-Control header of the response. The header is set
+// when Apply is called.
+func (ctx *Context) CacheControl() *CacheControlBuilder {
+	return &CacheControlBuilder{ctx: ctx}
+}
+
+// add adds the directive, replacing the directive of the same name.
+func (b *CacheControlBuilder) add(name, value string) *CacheControlBuilder {
+	directive := name
+	if value != "" {
+		directive += "=" + value
+	}
+
+	for i, d := range b.directives {
+		if d == name || strings.HasPrefix(d, name+"=") {
+			b.directives[i] = directive
+			return b
+		}
+	}
+	b.directives = append(b.directives, directive)
+
+	return b
+}
+
+// seconds formats the duration as delta-seconds.
+func seconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// MaxAge sets the max-age directive.
+func (b *CacheControlBuilder) MaxAge(d time.Duration) *CacheControlBuilder {
+	return b.add("max-age", seconds(d))
+}
+
+// SMaxAge sets the s-maxage directive for shared caches.
+func (b *CacheControlBuilder) SMaxAge(d time.Duration) *CacheControlBuilder {
+	return b.add("s-maxage", seconds(d))
+}
+
+// StaleWhileRevalidate sets the stale-while-revalidate directive.
+func (b *CacheControlBuilder) StaleWhileRevalidate(d time.Duration) *CacheControlBuilder {
+	return b.add("stale-while-revalidate", seconds(d))
+}
+
+// StaleIfError sets the stale-if-error directive.
+func (b *CacheControlBuilder) StaleIfError(d time.Duration) *CacheControlBuilder {
+	return b.add("stale-if-error", seconds(d))
+}
+
+// Public sets the public directive.
+func (b *CacheControlBuilder) Public() *CacheControlBuilder {
+	return b.add("public", "")
+}
+
+// Private sets the private directive.
+func (b *CacheControlBuilder) Private() *CacheControlBuilder {
+	return b.add("private", "")
+}
+
+// NoCache sets the no-cache directive.
+func (b *CacheControlBuilder) NoCache() *CacheControlBuilder {
+	return b.add("no-cache", "")
+}
+
+// NoStore sets the no-store directive.
+func (b *CacheControlBuilder) NoStore() *CacheControlBuilder {
+	return b.add("no-store", "")
+}
+
+// NoTransform sets the no-transform directive.
+func (b *CacheControlBuilder) NoTransform() *CacheControlBuilder {
+	return b.add("no-transform", "")
+}
+
+// MustRevalidate sets the must-revalidate directive.
+func (b *CacheControlBuilder) MustRevalidate() *CacheControlBuilder {
+	return b.add("must-revalidate", "")
+}
+
+// ProxyRevalidate sets the proxy-revalidate directive.
+func (b *CacheControlBuilder) ProxyRevalidate() *CacheControlBuilder {
+	return b.add("proxy-revalidate", "")
+}
+
+// Immutable sets the immutable directive.
+func (b *CacheControlBuilder) Immutable() *CacheControlBuilder {
+	return b.add("immutable", "")
+}
+
+// String returns the value of the Cache-Control header.
+func (b *CacheControlBuilder) String() string {
+	return strings.Join(b.directives, ", ")
+}
+
+// Apply sets the Cache-Control header of the response.
+func (b *CacheControlBuilder) Apply() {
+	if len(b.directives) == 0 {
+		b.ctx.DelHeader("Cache-Control")
+		return
+	}
+
+	b.ctx.SetHeader("Cache-Control", b.String())
+}
+
+// NoCache sets the Cache-Control header of the response to no-cache, so caches must revalidate
+// the response before using it.
+func (ctx *Context) NoCache() {
+	ctx.CacheControl().NoCache().Apply()
+}
+
+// NoStore sets the Cache-Control header of the response to no-store, so the response is never
+// stored by caches.
+func (ctx *Context) NoStore() {
+	ctx.CacheControl().NoStore().Apply()
+}
@@ -0,0 +1,131 @@
+package simple_context
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultCanonicalComponents are the components of the canonical request string when none is
+// given.
+var DefaultCanonicalComponents = []string{"method", "path", "query", "body"}
+
+// canonicalSource is the request that the canonical request string is built from.
+type canonicalSource struct {
+	method string
+	path   string
+	query  url.Values
+	header func(name string) []string
+	body   func() ([]byte, error)
+}
+
+// build builds the canonical request string of the components. Each component is serialized in a
+// line of "<component>:<value>":
+//
+//   - method: the uppercase request method.
+//   - path: the request path.
+//   - query: the query parameters sorted by the keys and values, escaped and joined by "&".
+//   - header:<name>: the trimmed values of the header joined by ",".
+//   - body: the lowercase hex SHA-256 digest of the request body.
+func (src canonicalSource) build(components []string) (string, error) {
+	if len(components) == 0 {
+		components = DefaultCanonicalComponents
+	}
+
+	lines := make([]string, 0, len(components))
+	for _, component := range components {
+		component = strings.ToLower(strings.TrimSpace(component))
+
+		var value string
+		switch {
+		case component == "method":
+			value = strings.ToUpper(src.method)
+		case component == "path":
+			value = src.path
+		case component == "query":
+			value = canonicalQuery(src.query)
+		case component == "body":
+			body, err := src.body()
+			if err != nil {
+				return "", err
+			}
+			sum := sha256.Sum256(body)
+			value = hex.EncodeToString(sum[:])
+		case strings.HasPrefix(component, "header:"):
+			values := src.header(strings.TrimPrefix(component, "header:"))
+			trimmed := make([]string, 0, len(values))
+			for _, v := range values {
+				trimmed = append(trimmed, strings.TrimSpace(v))
+			}
+			value = strings.Join(trimmed, ",")
+		default:
+			return "", errors.New("unknown canonical request component: " + component)
+		}
+
+		lines = append(lines, component+":"+value)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// canonicalQuery serializes the query parameters sorted by the keys and values.
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+// CanonicalRequestString returns the deterministic serialization of the components of the request,
+// for the signatures over the request. The components are "method", "path", "query",
+// "header:<name>", and "body", DefaultCanonicalComponents are used if none is given. The method is
+// the one sent by the client, without the method override.
+func (ctx *Context) CanonicalRequestString(components ...string) (string, error) {
+	return canonicalSource{
+		method: ctx.OriginalMethod(),
+		path:   ctx.Path(),
+		query:  ctx.Queries(),
+		header: ctx.HeaderValues,
+		body:   ctx.Body,
+	}.build(components)
+}
+
+// CanonicalRequestStringOf returns the canonical request string of the outgoing request, so the
+// clients can sign the requests symmetrically with the servers. The body of the request is read
+// and replaced with an equivalent reader.
+func CanonicalRequestStringOf(req *http.Request, components ...string) (string, error) {
+	return canonicalSource{
+		method: req.Method,
+		path:   req.URL.Path,
+		query:  req.URL.Query(),
+		header: func(name string) []string {
+			if strings.EqualFold(name, "host") {
+				return []string{req.Host}
+			}
+			return req.Header.Values(name)
+		},
+		body: func() ([]byte, error) {
+			if req.Body == nil || req.Body == http.NoBody {
+				return nil, nil
+			}
+			body, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			return body, nil
+		},
+	}.build(components)
+}
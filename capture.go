@@ -0,0 +1,43 @@
+package simple_context
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// CapturedResponse is a response recorded by the context.
+type CapturedResponse struct {
+	// Status is the status code of the response.
+	Status int
+	// Header is the recorded headers of the response.
+	Header http.Header
+	// Body is the body of the response before it's encoded by the response compression.
+	Body []byte
+}
+
+// CaptureResponse starts recording the response body written through the context after it's
+// called, so it can be retrieved by CapturedResponse.
+func (ctx *Context) CaptureResponse() {
+	if ctx.capture == nil {
+		ctx.capture = new(bytes.Buffer)
+	}
+}
+
+// CapturedResponse returns the recorded response with the status code, the values of the headers
+// of the names, and the body recorded since CaptureResponse is called.
+func (ctx *Context) CapturedResponse(headers ...string) CapturedResponse {
+	res := CapturedResponse{
+		Status: ctx.status,
+		Header: make(http.Header, len(headers)),
+	}
+	for _, name := range headers {
+		if value := ctx.GetHeader(name); value != "" {
+			res.Header.Set(name, value)
+		}
+	}
+	if ctx.capture != nil {
+		res.Body = append([]byte(nil), ctx.capture.Bytes()...)
+	}
+
+	return res
+}
@@ -0,0 +1,271 @@
+package simple_context
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-amwk/core"
+)
+
+// chainCache is the cache of the composed handler chains, keyed by the route.
+var chainCache sync.Map
+
+// UseCachedChain adds the handler chain of the route to the context. The chain is composed by the
+// build function on the first use of the route, and the composed chain is shared by all contexts
+// of the route after that, so the same middleware sequence is not composed for every request. The
+// contexts of the route initialized after the chain is composed have it attached by InitContext,
+// and calling UseCachedChain for them again does nothing. The shared chain is never modified, the
+// handlers added to the context later are appended to a copy.
+func (ctx *Context) UseCachedChain(route string, build func() []core.HandlerFunc) {
+	if ctx.cachedChainRoute == route {
+		return
+	}
+
+	var chain []core.HandlerFunc
+	if cached, ok := chainCache.Load(route); ok {
+		chain = cached.([]core.HandlerFunc)
+	} else {
+		handlers := build()
+		chain = make([]core.HandlerFunc, len(handlers))
+		copy(chain, handlers)
+
+		cached, _ := chainCache.LoadOrStore(route, chain)
+		chain = cached.([]core.HandlerFunc)
+	}
+
+	ctx.useSharedChain(route, chain)
+}
+
+// attachCachedChain attaches the cached handler chain of the resource of the request, if it has
+// been composed by UseCachedChain.
+func (ctx *Context) attachCachedChain() {
+	route := ctx.Resource()
+	if route == "" {
+		return
+	}
+
+	if cached, ok := chainCache.Load(route); ok {
+		ctx.useSharedChain(route, cached.([]core.HandlerFunc))
+	}
+}
+
+// useSharedChain adds the shared chain of the route to the context.
+func (ctx *Context) useSharedChain(route string, chain []core.HandlerFunc) {
+	ctx.cachedChainRoute = route
+
+	if len(ctx.handlers) == 0 {
+		// the length and the capacity of the shared chains are equal, so appending to them
+		// always allocates a new array.
+		ctx.handlers = chain
+		return
+	}
+
+	ctx.Use(chain...)
+}
+
+// InvalidateCachedChain removes the cached handler chain of the route, so it will be composed
+// again on the next use.
+func InvalidateCachedChain(route string) {
+	chainCache.Delete(route)
+}
+
+// HandlerInfo describes a handler in the chain of a context.
+type HandlerInfo struct {
+	// Index is the position of the handler in the chain.
+	Index int `json:"index"`
+	// Name is the fully-qualified function name of the handler.
+	Name string `json:"name"`
+	// File is the source file where the handler is defined.
+	File string `json:"file,omitempty"`
+	// Line is the line in the source file where the handler is defined.
+	Line int `json:"line,omitempty"`
+}
+
+// Chain returns the descriptions of the handlers in the chain of the context, in the order they
+// are executed.
+func (ctx *Context) Chain() []HandlerInfo {
+	infos := make([]HandlerInfo, 0, len(ctx.handlers))
+	for i, handler := range ctx.handlers {
+		info := HandlerInfo{Index: i, Name: "unknown"}
+		if fn := handlerFunc(handler); fn != nil {
+			info.Name = fn.Name()
+			info.File, info.Line = fn.FileLine(fn.Entry())
+		}
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// ChainJSON exports the handler chain of the context as a JSON array.
+func (ctx *Context) ChainJSON() ([]byte, error) {
+	return json.MarshalIndent(ctx.Chain(), "", "  ")
+}
+
+// ChainDOT exports the handler chain of the context as a Graphviz DOT digraph.
+func (ctx *Context) ChainDOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph chain {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, info := range ctx.Chain() {
+		label := info.Name
+		if info.File != "" {
+			label += "\\n" + filepath.Base(info.File) + ":" + strconv.Itoa(info.Line)
+		}
+		sb.WriteString("\th" + strconv.Itoa(info.Index) + " [label=" + strconv.Quote(label) + "];\n")
+		if info.Index > 0 {
+			sb.WriteString("\th" + strconv.Itoa(info.Index-1) + " -> h" + strconv.Itoa(info.Index) + ";\n")
+		}
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// ChainDebugHandler is a handler that responds the handler chain of the context it's executed in,
+// as DOT if the format query parameter is "dot" or as JSON otherwise. It's intended to be mounted
+// on a debug route to audit which middleware runs for the route.
+func ChainDebugHandler(c core.Context) {
+	ctx, ok := c.(*Context)
+	if !ok {
+		return
+	}
+
+	if ctx.Query("format") == "dot" {
+		ctx.render(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(ctx.ChainDOT()))
+		return
+	}
+
+	body, err := ctx.ChainJSON()
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	ctx.render(http.StatusOK, mimeJSON+"; charset=utf-8", body)
+}
+
+var defaultChain atomic.Pointer[[]core.HandlerFunc]
+
+// SetDefaultChain atomically replaces the default handler chain, which is attached to the contexts
+// when they're initialized. The in-flight requests keep the chain they were initialized with, so
+// middleware like debug handlers can be enabled at runtime without racing them.
+func SetDefaultChain(handlers ...core.HandlerFunc) {
+	chain := make([]core.HandlerFunc, len(handlers))
+	copy(chain, handlers)
+	defaultChain.Store(&chain)
+}
+
+// DefaultChain returns a copy of the default handler chain.
+func DefaultChain() []core.HandlerFunc {
+	chain := defaultChain.Load()
+	if chain == nil {
+		return nil
+	}
+
+	return append([]core.HandlerFunc(nil), (*chain)...)
+}
+
+// attachDefaultChain attaches the shared default handler chain to the context, it's never modified
+// by the handlers added to the context later, as UseCachedChain explains.
+func (ctx *Context) attachDefaultChain() {
+	if chain := defaultChain.Load(); chain != nil && len(*chain) > 0 {
+		ctx.handlers = *chain
+	} else {
+		ctx.handlers = nil
+	}
+}
+
+// HandlerName returns the function name of the handler being executed, or an empty string if no
+// handler is being executed.
+func (ctx *Context) HandlerName() string {
+	if ctx.index < 0 || ctx.index >= len(ctx.handlers) {
+		return ""
+	}
+
+	return handlerName(ctx.handlers[ctx.index])
+}
+
+// HandlerNames returns the function names of the handlers in the chain.
+func (ctx *Context) HandlerNames() []string {
+	names := make([]string, 0, len(ctx.handlers))
+	for _, handler := range ctx.handlers {
+		names = append(names, handlerName(handler))
+	}
+
+	return names
+}
+
+// UseNext inserts the handlers right after the handler being executed, so they're executed next,
+// or at the front of the chain if the execution hasn't started. The chain is copied, the shared
+// chains like the default chain are never modified.
+func (ctx *Context) UseNext(handlers ...core.HandlerFunc) {
+	ctx.insertHandlers(ctx.index+1, handlers)
+}
+
+// UseFront prepends the handlers to the chain. It should be called before the execution starts,
+// the handlers prepended after that are skipped as they're before the handler being executed. The
+// chain is copied, the shared chains like the default chain are never modified.
+func (ctx *Context) UseFront(handlers ...core.HandlerFunc) {
+	ctx.insertHandlers(0, handlers)
+	if ctx.index >= 0 {
+		ctx.index += len(handlers)
+	}
+}
+
+// insertHandlers inserts the handlers at the position of the chain into a new chain.
+func (ctx *Context) insertHandlers(pos int, handlers []core.HandlerFunc) {
+	if len(handlers) == 0 {
+		return
+	}
+	pos = min(max(pos, 0), len(ctx.handlers))
+
+	chain := make([]core.HandlerFunc, 0, len(ctx.handlers)+len(handlers))
+	chain = append(chain, ctx.handlers[:pos]...)
+	chain = append(chain, handlers...)
+	chain = append(chain, ctx.handlers[pos:]...)
+	ctx.handlers = chain
+}
+
+// Skip skips the next n handlers after the handler being executed, without aborting the rest of
+// the chain.
+func (ctx *Context) Skip(n int) {
+	if n <= 0 {
+		return
+	}
+
+	ctx.index = min(ctx.index+n, len(ctx.handlers))
+}
+
+// SkipIf skips the next n handlers as Skip does if the predicate reports true for the context.
+func (ctx *Context) SkipIf(pred func(*Context) bool, n int) {
+	if pred(ctx) {
+		ctx.Skip(n)
+	}
+}
+
+// Remaining returns the count of the handlers after the handler being executed.
+func (ctx *Context) Remaining() int {
+	return max(len(ctx.handlers)-ctx.index-1, 0)
+}
+
+// IsLastHandler checks if the handler being executed is the last handler of the chain.
+func (ctx *Context) IsLastHandler() bool {
+	return ctx.index >= 0 && ctx.index == len(ctx.handlers)-1
+}
+
+// Restart replaces the chain with the handlers and executes it against the same request, after
+// resetting the abort flag, for the internal redirects to the error pages or the fallback handlers.
+// The handlers of the previous chain after the one calling Restart are not executed. The response
+// status and body must not be written before restarting.
+func (ctx *Context) Restart(handlers ...core.HandlerFunc) {
+	defer ctx.enterChain()()
+
+	ctx.handlers = append([]core.HandlerFunc(nil), handlers...)
+	ctx.isAbort = false
+	ctx.index = 0
+	ctx.runChain()
+}
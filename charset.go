@@ -0,0 +1,90 @@
+package simple_context
+
+import (
+	"errors"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedCharset is returned if the request body is encoded in a charset that has no
+// registered decoder.
+var ErrUnsupportedCharset = errors.New("unsupported charset")
+
+// CharsetDecoder transcodes the data in a charset to UTF-8.
+type CharsetDecoder func(data []byte) ([]byte, error)
+
+var charsetDecoders = map[string]CharsetDecoder{
+	"utf-8":      decodeUTF8,
+	"utf8":       decodeUTF8,
+	"us-ascii":   decodeUTF8,
+	"iso-8859-1": decodeLatin1,
+	"latin1":     decodeLatin1,
+}
+
+// RegisterCharset registers the decoder of the charset, like "shift_jis" or "windows-1252", for
+// transcoding request bodies to UTF-8. It should be called before serving requests.
+func RegisterCharset(charset string, decoder CharsetDecoder) {
+	charsetDecoders[strings.ToLower(charset)] = decoder
+}
+
+// Charset returns the lowercase charset parameter of the Content-Type header of the request, or
+// an empty string if it's absent.
+func (ctx *Context) Charset() string {
+	_, params, err := mime.ParseMediaType(ctx.Header("Content-Type"))
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(params["charset"])
+}
+
+// BodyText returns the request body transcoded from the charset of the request to UTF-8. The body
+// is treated as UTF-8 if the request has no charset.
+func (ctx *Context) BodyText() (string, error) {
+	body, err := ctx.utf8Body()
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// utf8Body returns the request body transcoded from the charset of the request to UTF-8.
+func (ctx *Context) utf8Body() ([]byte, error) {
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, err
+	}
+
+	charset := ctx.Charset()
+	if charset == "" {
+		return body, nil
+	}
+
+	decoder, ok := charsetDecoders[charset]
+	if !ok {
+		return nil, ErrUnsupportedCharset
+	}
+
+	return decoder(body)
+}
+
+// decodeUTF8 validates the UTF-8 data.
+func decodeUTF8(data []byte) ([]byte, error) {
+	if !utf8.Valid(data) {
+		return nil, errors.New("invalid utf-8 data")
+	}
+
+	return data, nil
+}
+
+// decodeLatin1 transcodes the ISO-8859-1 data to UTF-8.
+func decodeLatin1(data []byte) ([]byte, error) {
+	buf := make([]byte, 0, len(data))
+	for _, b := range data {
+		buf = utf8.AppendRune(buf, rune(b))
+	}
+
+	return buf, nil
+}
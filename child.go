@@ -0,0 +1,235 @@
+package simple_context
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-amwk/core"
+)
+
+// childContext is the core context of a sub-request. It delegates to the core context of the
+// parent except for the request and the response.
+type childContext struct {
+	contextImpl
+
+	req *childRequest
+	res *childResponse
+}
+
+// Request returns the request of the sub-request.
+func (c *childContext) Request() core.Request {
+	return c.req
+}
+
+// Response returns the captured response of the sub-request.
+func (c *childContext) Response() core.Response {
+	return c.res
+}
+
+// childRequest is the request of a sub-request. It inherits the headers and the connection
+// properties of the parent request.
+type childRequest struct {
+	core.Request
+
+	method string
+	path   string
+	query  url.Values
+	body   []byte
+}
+
+// Method returns the method of the sub-request.
+func (r *childRequest) Method() string {
+	return r.method
+}
+
+// Path returns the path of the sub-request.
+func (r *childRequest) Path() string {
+	return r.path
+}
+
+// Resource returns the path of the sub-request, as it's not routed by the router.
+func (r *childRequest) Resource() string {
+	return r.path
+}
+
+// PathValue returns an empty string, as the sub-request is not routed by the router.
+func (r *childRequest) PathValue(string) string {
+	return ""
+}
+
+// Queries returns the query parameters of the sub-request.
+func (r *childRequest) Queries() url.Values {
+	return r.query
+}
+
+// Query returns the first value of the query parameter of the sub-request.
+func (r *childRequest) Query(key string) string {
+	return r.query.Get(key)
+}
+
+// QueryValues returns all values of the query parameter of the sub-request.
+func (r *childRequest) QueryValues(key string) []string {
+	return r.query[key]
+}
+
+// Body returns the body of the sub-request.
+func (r *childRequest) Body() ([]byte, error) {
+	return r.body, nil
+}
+
+// ContentLength returns the length of the body of the sub-request.
+func (r *childRequest) ContentLength() int64 {
+	return int64(len(r.body))
+}
+
+// childBodyHeaders are the headers of the parent body that don't apply to the sub-request body.
+var childBodyHeaders = []string{"Content-Encoding", "Content-Length"}
+
+// Header retrieves a header value by name from the parent request.
+func (r *childRequest) Header(key string) string {
+	return r.Headers().Get(key)
+}
+
+// HeaderValues retrieves all values for a header by name from the parent request.
+func (r *childRequest) HeaderValues(key string) []string {
+	return r.Headers().Values(key)
+}
+
+// Headers returns the headers of the parent request, excluding the headers of the parent body.
+func (r *childRequest) Headers() http.Header {
+	headers := r.Request.Headers().Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	for _, key := range childBodyHeaders {
+		headers.Del(key)
+	}
+
+	return headers
+}
+
+// RawRequest returns nil, as the sub-request has no net/http request.
+func (r *childRequest) RawRequest() *http.Request {
+	return nil
+}
+
+// childResponse is the captured response of a sub-request.
+type childResponse struct {
+	core.Response
+
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// AddHeader adds a header to the response.
+func (r *childResponse) AddHeader(key, value string) {
+	r.header.Add(key, value)
+}
+
+// SetHeader sets a header in the response.
+func (r *childResponse) SetHeader(key, value string) {
+	r.header.Set(key, value)
+}
+
+// GetHeader retrieves a header value by name from the response.
+func (r *childResponse) GetHeader(key string) string {
+	return r.header.Get(key)
+}
+
+// DelHeader removes a header from the response.
+func (r *childResponse) DelHeader(key string) {
+	r.header.Del(key)
+}
+
+// Status sets the status code of the response.
+func (r *childResponse) Status(code int) error {
+	r.status = code
+	return nil
+}
+
+// Write writes data to the response body.
+func (r *childResponse) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(data)
+}
+
+// Flush does nothing, as the response is captured.
+func (r *childResponse) Flush() {}
+
+// RawResponseWriter returns nil, as the sub-request has no net/http response writer.
+func (r *childResponse) RawResponseWriter() http.ResponseWriter {
+	return nil
+}
+
+// WriteInformational discards the informational responses of the sub-request.
+func (r *childResponse) WriteInformational(int, http.Header) error {
+	return ErrInformationalUnsupported
+}
+
+// NewChildContext creates a context of an internal sub-request to the path, which may have a
+// query, for composing the responses of internal endpoints without HTTP round trips. The child
+// inherits the headers, the state (including the authenticated principal), the locale, the trace
+// annotations, the deadline, and the configuration snapshot of the parent, and has its own
+// response that is captured instead of sent. The handlers of the child are added by Use and
+// executed by Next, and the captured response is returned by ChildResponse.
+func NewChildContext(parent *Context, method, path string, body []byte) *Context {
+	path, rawQuery, _ := strings.Cut(path, "?")
+	query, _ := url.ParseQuery(rawQuery)
+
+	impl := &childContext{
+		contextImpl: parent.contextImpl,
+		req: &childRequest{
+			Request: parent.Request(),
+			method:  strings.ToUpper(method),
+			path:    path,
+			query:   query,
+			body:    body,
+		},
+		res: &childResponse{
+			Response: parent.Response(),
+			header:   make(http.Header),
+		},
+	}
+
+	child := new(Context)
+	InitContext(child, impl)
+	child.handlers = nil
+	child.cachedChainRoute = ""
+
+	parent.state.Range(func(key, value any) bool {
+		child.state.Store(key, value)
+		return true
+	})
+	child.locale = parent.Locale()
+	child.clock = parent.clock
+	child.random = parent.random
+	child.config = parent.config
+	child.stdCtx = parent.StdContext()
+	child.traceCtx = parent.traceCtx
+	child.requestID = parent.requestID
+	for key, value := range parent.profileLabels {
+		child.SetProfileLabel(key, value)
+	}
+
+	return child
+}
+
+// ChildResponse returns the captured response of the context created by NewChildContext, or false
+// if the context is not a child.
+func (ctx *Context) ChildResponse() (CapturedResponse, bool) {
+	impl, ok := ctx.contextImpl.(*childContext)
+	if !ok {
+		return CapturedResponse{}, false
+	}
+
+	return CapturedResponse{
+		Status: impl.res.status,
+		Header: impl.res.header.Clone(),
+		Body:   append([]byte(nil), impl.res.body.Bytes()...),
+	}, true
+}
@@ -0,0 +1,72 @@
+package simple_context
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of the current time of the contexts, it can be replaced by a fake clock in
+// tests of time-dependent features.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to allow the use of ordinary functions as clocks.
+type ClockFunc func() time.Time
+
+// Now calls f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// systemClock is the clock of the system time.
+type systemClock struct{}
+
+// Now returns the current system time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// DefaultClock is the clock of the contexts, including the start time of the requests. It's the
+// system clock by default.
+var DefaultClock Clock = systemClock{}
+
+// SetClock sets the clock of the current request. The start time of the request is not changed.
+func (ctx *Context) SetClock(clock Clock) {
+	ctx.clock = clock
+}
+
+// Clock returns the clock of the current request.
+func (ctx *Context) Clock() Clock {
+	if ctx.clock == nil {
+		return DefaultClock
+	}
+
+	return ctx.clock
+}
+
+// Now returns the current time by the clock of the current request. All time reads of the context
+// go through it.
+func (ctx *Context) Now() time.Time {
+	return ctx.Clock().Now()
+}
+
+// clockContextKey is the key of the clock in the standard contexts.
+type clockContextKey struct{}
+
+// ClockFromContext returns the clock of the context passed to the key providers and other
+// integrations by the standard context, or DefaultClock if it's absent.
+func ClockFromContext(stdCtx context.Context) Clock {
+	if clock, ok := stdCtx.Value(clockContextKey{}).(Clock); ok {
+		return clock
+	}
+
+	return DefaultClock
+}
+
+// stdContextWithClock returns the standard context of the request carrying the clock of the
+// context, for ClockFromContext.
+func (ctx *Context) stdContextWithClock() context.Context {
+	return context.WithValue(ctx.StdContext(), clockContextKey{}, ctx.Clock())
+}
@@ -0,0 +1,253 @@
+package simple_context
+
+import (
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// acceptEncodingQuality returns the quality of the content coding in the Accept-Encoding header
+// of the request. The identity coding is acceptable unless it's excluded explicitly.
+func (ctx *Context) acceptEncodingQuality(coding string) float64 {
+	quality := -1.0
+	wildcard := -1.0
+
+	for _, value := range ParseQualityValues(strings.Join(ctx.HeaderValues("Accept-Encoding"), ",")) {
+		switch strings.ToLower(value.Value) {
+		case coding:
+			if quality < 0 {
+				quality = value.Quality
+			}
+		case "*":
+			if wildcard < 0 {
+				wildcard = value.Quality
+			}
+		}
+	}
+
+	if quality >= 0 {
+		return quality
+	}
+	if wildcard >= 0 {
+		return wildcard
+	}
+	if coding == "identity" {
+		return 1
+	}
+
+	return 0
+}
+
+// EncoderFactory creates an encoder of a content coding that writes the encoded data to the
+// writer.
+type EncoderFactory func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFactory{
+		"gzip": func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+	}
+	// encoderPreference is the preference order of the content codings on ties.
+	encoderPreference = []string{"br", "zstd", "gzip"}
+)
+
+// RegisterEncoder registers the encoder factory of the content coding, like "br" or "zstd", for
+// the response compression. It replaces the factory registered for the coding if any. The codings
+// br and zstd are preferred over gzip when the client accepts them equally.
+func RegisterEncoder(coding string, factory EncoderFactory) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	coding = strings.ToLower(coding)
+	encoders[coding] = factory
+
+	for _, preferred := range encoderPreference {
+		if preferred == coding {
+			return
+		}
+	}
+	encoderPreference = append(encoderPreference, coding)
+}
+
+// negotiateEncoding returns the registered content coding that is best acceptable by the client,
+// or an empty string if none of them is acceptable.
+func (ctx *Context) negotiateEncoding() string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	best := ""
+	bestQuality := 0.0
+	for _, coding := range encoderPreference {
+		if _, ok := encoders[coding]; !ok {
+			continue
+		}
+		if quality := ctx.acceptEncodingQuality(coding); quality > bestQuality {
+			best = coding
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// CompressionConfig is the configuration of the response compression policy.
+type CompressionConfig struct {
+	// MinSize is the minimum size in bytes of the responses to compress. The responses with a known
+	// size smaller than it are not compressed.
+	MinSize int64
+	// ContentTypes are the media types of the responses to compress, a type ends with "/*" matches
+	// all subtypes of it. The responses of all types are compressed if it's empty.
+	ContentTypes []string
+	// ExcludedRoutes are the resource patterns of the routes that are never compressed.
+	ExcludedRoutes []string
+}
+
+// Compression is the response compression policy of the contexts. By default, it skips the
+// responses smaller than 1 KiB and the media types that are usually already compressed.
+var Compression = CompressionConfig{
+	MinSize: 1024,
+	ContentTypes: []string{
+		"text/*",
+		"application/json",
+		"application/xml",
+		"application/javascript",
+		"application/x-www-form-urlencoded",
+		"application/problem+json",
+		"application/ld+json",
+		"application/xhtml+xml",
+		"image/svg+xml",
+	},
+}
+
+// DisableCompression opts the current request out of the response compression, for routes that
+// must not be compressed like Server-Sent Events.
+func (ctx *Context) DisableCompression() {
+	ctx.compressDisabled = true
+	ctx.compressCoding = ""
+}
+
+// Compress compresses the response body with the best content coding acceptable by the client,
+// and returns whether the response will be compressed. The compression is started when the status
+// code or the body is written, and it's skipped if the content type or the known size of the
+// response doesn't meet the compression policy. It sets the Content-Encoding and Vary headers, and
+// closes the encoder when the handler chain finishes. It must be called before the status code
+// and the body are written.
+func (ctx *Context) Compress() bool {
+	if ctx.encoder != nil || ctx.compressCoding != "" {
+		return true
+	}
+
+	if ctx.compressDisabled {
+		return false
+	}
+	resource := ctx.Resource()
+	for _, route := range Compression.ExcludedRoutes {
+		if route == resource {
+			return false
+		}
+	}
+
+	ctx.AddVary("Accept-Encoding")
+
+	if ctx.GetHeader("Content-Encoding") != "" {
+		return false
+	}
+
+	ctx.compressCoding = ctx.negotiateEncoding()
+
+	return ctx.compressCoding != ""
+}
+
+// startCompression starts the pending compression of the response if it meets the compression
+// policy. The size hint is the size of the response body, or a negative value if it's unknown.
+func (ctx *Context) startCompression(sizeHint int64) {
+	coding := ctx.compressCoding
+	if coding == "" {
+		return
+	}
+	ctx.compressCoding = ""
+
+	config := Compression
+	if sizeHint < 0 {
+		if contentLength, err := strconv.ParseInt(ctx.GetHeader("Content-Length"), 10, 64); err == nil {
+			sizeHint = contentLength
+		}
+	}
+	if sizeHint >= 0 && sizeHint < config.MinSize {
+		return
+	}
+	if !compressibleType(config.ContentTypes, ctx.GetHeader("Content-Type")) {
+		return
+	}
+
+	encodersMu.RLock()
+	factory := encoders[coding]
+	encodersMu.RUnlock()
+
+	encoder, err := factory(bodyWriter{ctx})
+	if err != nil {
+		return
+	}
+
+	ctx.SetHeader("Content-Encoding", coding)
+	ctx.DelHeader("Content-Length")
+
+	ctx.encoder = encoder
+	ctx.onFinish(func() {
+		if ctx.encoder == encoder {
+			encoder.Close()
+			ctx.encoder = nil
+		}
+	})
+}
+
+// flushHeaders decides the pending compression of the response by the headers and the size hint,
+// and sends the pending status code. It's called right before the body is written.
+func (ctx *Context) flushHeaders(sizeHint int64) error {
+	ctx.startCompression(sizeHint)
+	if !ctx.statusPending {
+		return nil
+	}
+
+	ctx.statusPending = false
+	return ctx.Response().Status(ctx.status)
+}
+
+// flushPendingStatus sends the pending status code of the response without a body when the
+// handler chain finishes, the empty body is never compressed.
+func (ctx *Context) flushPendingStatus() {
+	if ctx.statusPending {
+		ctx.compressCoding = ""
+		_ = ctx.flushHeaders(-1)
+	}
+}
+
+// compressibleType checks if the content type matches one of the media types.
+func compressibleType(mediaTypes []string, contentType string) bool {
+	if len(mediaTypes) == 0 {
+		return true
+	}
+
+	contentType, _, _ = strings.Cut(contentType, ";")
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return false
+	}
+
+	for _, mediaType := range mediaTypes {
+		mediaType = strings.ToLower(mediaType)
+		if prefix, ok := strings.CutSuffix(mediaType, "*"); ok {
+			if strings.HasPrefix(contentType, prefix) {
+				return true
+			}
+		} else if mediaType == contentType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,272 @@
+package simple_context
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// testGunzip returns the data decompressed by gzip.
+func testGunzip(t *testing.T, data []byte) string {
+	t.Helper()
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	return string(decoded)
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("compressible text ", 100)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		resource       string
+		contentType    string
+		body           string
+		setup          func(ctx *Context)
+		wantEncoding   string
+		wantCompress   bool
+		// noVary is set if the response doesn't vary by the Accept-Encoding header.
+		noVary bool
+	}{
+		{
+			name:           "gzip",
+			acceptEncoding: "gzip, deflate",
+			contentType:    "text/plain",
+			body:           large,
+			wantEncoding:   "gzip",
+			wantCompress:   true,
+		},
+		{
+			name:           "wildcard",
+			acceptEncoding: "*",
+			contentType:    "application/json",
+			body:           large,
+			wantEncoding:   "gzip",
+			wantCompress:   true,
+		},
+		{
+			name:           "smaller than the min size",
+			acceptEncoding: "gzip",
+			contentType:    "text/plain",
+			body:           "small",
+			setup:          func(ctx *Context) { ctx.SetHeader("Content-Length", "5") },
+			wantCompress:   true,
+		},
+		{
+			name:           "incompressible type",
+			acceptEncoding: "gzip",
+			contentType:    "image/png",
+			body:           large,
+			wantCompress:   true,
+		},
+		{
+			name:           "not accepted",
+			acceptEncoding: "identity",
+			contentType:    "text/plain",
+			body:           large,
+		},
+		{
+			name:           "excluded by zero quality",
+			acceptEncoding: "gzip;q=0, *",
+			contentType:    "text/plain",
+			body:           large,
+		},
+		{
+			name:           "excluded route",
+			acceptEncoding: "gzip",
+			resource:       "/events",
+			contentType:    "text/plain",
+			body:           large,
+			noVary:         true,
+		},
+		{
+			name:           "disabled",
+			acceptEncoding: "gzip",
+			contentType:    "text/plain",
+			body:           large,
+			setup:          func(ctx *Context) { ctx.DisableCompression() },
+			noVary:         true,
+		},
+		{
+			name:           "already encoded",
+			acceptEncoding: "gzip",
+			contentType:    "text/plain",
+			body:           large,
+			setup:          func(ctx *Context) { ctx.SetHeader("Content-Encoding", "br") },
+			wantEncoding:   "br",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Compression
+			config.ExcludedRoutes = []string{"/events"}
+			setTestValue(t, &Compression, config)
+
+			impl := newTestCore(http.MethodGet, "/")
+			impl.req.resource = tt.resource
+			impl.req.header.Set("Accept-Encoding", tt.acceptEncoding)
+			runTestCore(t, impl, func(c core.Context) {
+				ctx := c.(*Context)
+				if tt.setup != nil {
+					tt.setup(ctx)
+				}
+				if got := ctx.Compress(); got != tt.wantCompress {
+					t.Errorf("Compress() = %v, want %v", got, tt.wantCompress)
+				}
+				ctx.SetHeader("Content-Type", tt.contentType)
+				if _, err := ctx.Write([]byte(tt.body)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			})
+
+			if got := impl.res.header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			body := string(impl.res.body)
+			if tt.wantEncoding == "gzip" {
+				body = testGunzip(t, impl.res.body)
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if vary := impl.res.header.Get("Vary") == "Accept-Encoding"; vary == tt.noVary {
+				t.Errorf("Vary = %q, want Accept-Encoding %v", impl.res.header.Get("Vary"), !tt.noVary)
+			}
+		})
+	}
+}
+
+func TestCompressDecidedWhenHeadersFlushed(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      func(ctx *Context)
+		wantStatus   int
+		wantEncoding string
+	}{
+		{
+			name: "headers set after the status",
+			handler: func(ctx *Context) {
+				_ = ctx.Status(http.StatusCreated)
+				ctx.SetHeader("Content-Type", "text/plain")
+				_, _ = ctx.Write([]byte(strings.Repeat("a", 2048)))
+			},
+			wantStatus:   http.StatusCreated,
+			wantEncoding: "gzip",
+		},
+		{
+			name: "content length set after the status",
+			handler: func(ctx *Context) {
+				_ = ctx.Status(http.StatusOK)
+				ctx.SetHeader("Content-Type", "text/plain")
+				ctx.SetHeader("Content-Length", "10")
+				_, _ = ctx.Write([]byte("0123456789"))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "status without body",
+			handler: func(ctx *Context) {
+				ctx.SetHeader("Content-Type", "text/plain")
+				_ = ctx.Status(http.StatusNoContent)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "rendered",
+			handler: func(ctx *Context) {
+				_ = ctx.String(http.StatusAccepted, strings.Repeat("a", 2048))
+			},
+			wantStatus:   http.StatusAccepted,
+			wantEncoding: "gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := newTestCore(http.MethodGet, "/")
+			impl.req.header.Set("Accept-Encoding", "gzip")
+			runTestCore(t, impl, func(c core.Context) {
+				ctx := c.(*Context)
+				ctx.Compress()
+				tt.handler(ctx)
+			})
+
+			if impl.res.status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", impl.res.status, tt.wantStatus)
+			}
+			if got := impl.res.header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if tt.wantEncoding != "" && impl.res.header.Get("Content-Length") != "" {
+				t.Errorf("Content-Length = %q, want none", impl.res.header.Get("Content-Length"))
+			}
+		})
+	}
+}
+
+// nopEncoder is an encoder writing the data as is, for the tests of the coding preference.
+type nopEncoder struct {
+	io.Writer
+}
+
+func (nopEncoder) Close() error { return nil }
+
+func TestRegisterEncoder(t *testing.T) {
+	encodersMu.Lock()
+	oldEncoders := make(map[string]EncoderFactory, len(encoders))
+	for coding, factory := range encoders {
+		oldEncoders[coding] = factory
+	}
+	oldPreference := append([]string(nil), encoderPreference...)
+	encodersMu.Unlock()
+	t.Cleanup(func() {
+		encodersMu.Lock()
+		encoders, encoderPreference = oldEncoders, oldPreference
+		encodersMu.Unlock()
+	})
+
+	RegisterEncoder("BR", func(w io.Writer) (io.WriteCloser, error) {
+		return nopEncoder{w}, nil
+	})
+	RegisterEncoder("x-test", func(w io.Writer) (io.WriteCloser, error) {
+		return nopEncoder{w}, nil
+	})
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		want           string
+	}{
+		{name: "preferred on ties", acceptEncoding: "gzip, br", want: "br"},
+		{name: "quality", acceptEncoding: "gzip, br;q=0.5", want: "gzip"},
+		{name: "registered coding", acceptEncoding: "x-test", want: "x-test"},
+		{name: "none", acceptEncoding: "deflate", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := newTestCore(http.MethodGet, "/")
+			impl.req.header.Set("Accept-Encoding", tt.acceptEncoding)
+			runTestCore(t, impl, func(c core.Context) {
+				if got := c.(*Context).negotiateEncoding(); got != tt.want {
+					t.Errorf("negotiateEncoding() = %q, want %q", got, tt.want)
+				}
+			})
+		})
+	}
+}
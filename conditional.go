@@ -0,0 +1,214 @@
+package simple_context
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// formatETag returns the entity tag in the quoted form, with the W/ prefix if it's weak. The tags
+// already in the quoted form are returned as they are.
+func formatETag(etag string, weak bool) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+
+	etag = `"` + etag + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+
+	return etag
+}
+
+// etagOpaque returns the opaque tag of the entity tag without the weak prefix.
+func etagOpaque(etag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+}
+
+// etagListContains checks if the comma-separated entity tags in the header values contain the
+// entity tag by the weak comparison, or if they're "*".
+func etagListContains(values []string, etag string) bool {
+	opaque := etagOpaque(etag)
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || (tag != "" && etagOpaque(tag) == opaque) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SetETag sets the ETag header of the response. The entity tag is quoted if it's not, and it's
+// marked as weak if weak is true.
+func (ctx *Context) SetETag(etag string, weak bool) {
+	ctx.SetHeader("ETag", formatETag(etag, weak))
+}
+
+// CheckETag compares the entity tag with the If-None-Match header of the request by the weak
+// comparison. If the entity tag matches for a GET or HEAD request, it responds with 304 Not
+// Modified with the ETag header, aborts the context, and returns true, so the handler can return
+// without rendering the body.
+func (ctx *Context) CheckETag(etag string) bool {
+	method := ctx.Method()
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+
+	ifNoneMatch := ctx.HeaderValues("If-None-Match")
+	if len(ifNoneMatch) == 0 || !etagListContains(ifNoneMatch, formatETag(etag, false)) {
+		return false
+	}
+
+	ctx.notModified(formatETag(etag, false))
+	return true
+}
+
+// notModified responds with 304 Not Modified and aborts the context. The representation headers
+// that are meaningless without a body are removed.
+func (ctx *Context) notModified(etag string) {
+	if etag != "" && ctx.GetHeader("ETag") == "" {
+		ctx.SetHeader("ETag", etag)
+	}
+	ctx.DelHeader("Content-Type")
+	ctx.DelHeader("Content-Length")
+	ctx.DelHeader("Content-Encoding")
+	ctx.compressCoding = ""
+
+	ctx.Abort()
+	_ = ctx.Status(http.StatusNotModified)
+}
+
+// AutoETag enables the automatic entity tags of the rendered responses. When it's enabled, the
+// render helpers hash the successful responses of GET and HEAD requests, set the ETag header if
+// it's not set, and respond with 304 Not Modified if the request revalidates the same body.
+var AutoETag = false
+
+// applyAutoETag sets the entity tag hashed from the rendered body, and returns true if the request
+// is answered with 304 Not Modified.
+func (ctx *Context) applyAutoETag(code int, body []byte) bool {
+	if !AutoETag || code != http.StatusOK {
+		return false
+	}
+
+	method := ctx.Method()
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+
+	etag := ctx.GetHeader("ETag")
+	if etag == "" {
+		sum := sha256.Sum256(body)
+		etag = formatETag(base64.RawURLEncoding.EncodeToString(sum[:16]), false)
+		ctx.SetHeader("ETag", etag)
+	}
+
+	return ctx.CheckETag(etag)
+}
+
+// SetLastModified sets the Last-Modified header of the response. The time is truncated to seconds
+// as the HTTP-date format has no sub-second precision.
+func (ctx *Context) SetLastModified(t time.Time) {
+	ctx.SetHeader("Last-Modified", t.UTC().Truncate(time.Second).Format(http.TimeFormat))
+}
+
+// NotModifiedSince compares the modification time with the If-Modified-Since header of the
+// request in second granularity. If the resource is not modified since then for a GET or HEAD
+// request, it responds with 304 Not Modified with the Last-Modified header, aborts the context,
+// and returns true. The header is ignored if the request has an If-None-Match header, which takes
+// precedence over it.
+func (ctx *Context) NotModifiedSince(t time.Time) bool {
+	method := ctx.Method()
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	if ctx.Header("If-None-Match") != "" || t.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ctx.Header("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	if t.Truncate(time.Second).After(since) {
+		return false
+	}
+
+	if ctx.GetHeader("Last-Modified") == "" {
+		ctx.SetLastModified(t)
+	}
+	ctx.notModified("")
+	return true
+}
+
+// RequirePreconditions enforces the conditional requests for PUT, PATCH, and DELETE. When it's
+// enabled, CheckPreconditions fails with 428 Precondition Required for the requests of the methods
+// that have neither If-Match nor If-Unmodified-Since.
+var RequirePreconditions = false
+
+// CheckPreconditions evaluates the If-Match and If-Unmodified-Since headers of the request against
+// the current entity tag and modification time of the resource, for optimistic locking. It returns
+// 412 Precondition Failed and false if the client's representation is stale, or 428 Precondition
+// Required and false if RequirePreconditions is enabled and the request is unconditional. It
+// returns 0 and true if the request can proceed. It doesn't write the response, so the handler can
+// render the error in its own format. The empty entity tag and the zero time mean the resource
+// doesn't exist or doesn't support the validator.
+func (ctx *Context) CheckPreconditions(currentETag string, lastModified time.Time) (int, bool) {
+	ifMatch := ctx.HeaderValues("If-Match")
+	ifUnmodifiedSince := ctx.Header("If-Unmodified-Since")
+
+	if len(ifMatch) == 0 && ifUnmodifiedSince == "" {
+		switch ctx.Method() {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			if RequirePreconditions {
+				return http.StatusPreconditionRequired, false
+			}
+		}
+		return 0, true
+	}
+
+	if len(ifMatch) > 0 {
+		if !etagListStrongContains(ifMatch, currentETag) {
+			return http.StatusPreconditionFailed, false
+		}
+		return 0, true
+	}
+
+	since, err := http.ParseTime(ifUnmodifiedSince)
+	if err != nil || lastModified.IsZero() {
+		return 0, true
+	}
+	if lastModified.Truncate(time.Second).After(since) {
+		return http.StatusPreconditionFailed, false
+	}
+
+	return 0, true
+}
+
+// etagListStrongContains checks if the comma-separated entity tags in the header values contain
+// the entity tag by the strong comparison. The weak tags never match, and "*" matches any existing
+// entity tag.
+func etagListStrongContains(values []string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	etag = formatETag(etag, false)
+	weak := strings.HasPrefix(etag, "W/")
+
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || (!weak && tag == etag) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
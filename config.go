@@ -0,0 +1,35 @@
+package simple_context
+
+import (
+	"sync/atomic"
+)
+
+// configSnapshot is a snapshot of the application configuration.
+type configSnapshot struct {
+	value any
+}
+
+var currentConfig atomic.Pointer[configSnapshot]
+
+// SetConfig replaces the application configuration. The contexts initialized after it's called
+// see the new configuration, the in-flight requests keep the snapshot taken at their start. The
+// configuration must not be modified after it's set, a new value should be set instead.
+func SetConfig(config any) {
+	currentConfig.Store(&configSnapshot{value: config})
+}
+
+// Config returns the snapshot of the application configuration taken when the context was
+// initialized, or nil if no configuration is set.
+func (ctx *Context) Config() any {
+	if ctx.config == nil {
+		return nil
+	}
+
+	return ctx.config.value
+}
+
+// ConfigAs returns the configuration snapshot of the context as the type T.
+func ConfigAs[T any](ctx *Context) (T, bool) {
+	config, ok := ctx.Config().(T)
+	return config, ok
+}
@@ -0,0 +1,181 @@
+package simple_context
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrDigestMissing is returned by VerifyContentDigest if the request has neither the
+	// Content-Digest nor the Repr-Digest header.
+	ErrDigestMissing = errors.New("digest missing")
+	// ErrDigestMismatch is returned by VerifyContentDigest if a digest doesn't match the body.
+	ErrDigestMismatch = errors.New("digest mismatch")
+	// ErrUnsupportedDigestAlgorithm is returned by VerifyContentDigest if none of the algorithms
+	// of a digest header is supported.
+	ErrUnsupportedDigestAlgorithm = errors.New("unsupported digest algorithm")
+)
+
+var (
+	digestAlgorithmsMu sync.RWMutex
+	// digestAlgorithms are the supported algorithms of the Content-Digest and Repr-Digest headers,
+	// by their names in the Hash Algorithms for HTTP Digest Fields registry.
+	digestAlgorithms = map[string]func() hash.Hash{
+		"sha-256": sha256.New,
+		"sha-512": sha512.New,
+	}
+)
+
+// DefaultDigestAlgorithm is the algorithm of the digests computed by SetContentDigest if none is
+// specified.
+var DefaultDigestAlgorithm = "sha-256"
+
+// RegisterDigestAlgorithm registers the hash function of the digest algorithm.
+func RegisterDigestAlgorithm(name string, h func() hash.Hash) {
+	digestAlgorithmsMu.Lock()
+	defer digestAlgorithmsMu.Unlock()
+
+	digestAlgorithms[strings.ToLower(name)] = h
+}
+
+// digestAlgorithm returns the hash function of the digest algorithm.
+func digestAlgorithm(name string) (func() hash.Hash, bool) {
+	digestAlgorithmsMu.RLock()
+	defer digestAlgorithmsMu.RUnlock()
+
+	h, ok := digestAlgorithms[name]
+	return h, ok
+}
+
+// VerifyContentDigest verifies the Content-Digest and Repr-Digest headers (RFC 9530) of the request
+// against the body as received, before the content coding is decoded. Every supported algorithm in
+// the headers must match, and the unsupported ones are ignored unless none is supported. The body
+// is cached, so it can be read or bound again after the verification.
+func (ctx *Context) VerifyContentDigest() error {
+	headers := []string{"Content-Digest", "Repr-Digest"}
+	present := false
+	for _, name := range headers {
+		value := strings.Join(ctx.HeaderValues(name), ",")
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		present = true
+
+		if _, err := ctx.Body(); err != nil {
+			return err
+		}
+		if err := verifyDigestField(value, ctx.encodedBody); err != nil {
+			return err
+		}
+	}
+
+	if !present {
+		return ErrDigestMissing
+	}
+
+	return nil
+}
+
+// SetContentDigest sets the Content-Digest header of the response to the digests of the body by
+// the algorithms, or DefaultDigestAlgorithm if none is specified. The body must be the whole body
+// that will be written, and the compression of the response is disabled so the digest is computed
+// over the content as sent.
+func (ctx *Context) SetContentDigest(body []byte, algorithms ...string) error {
+	value, err := ContentDigest(body, algorithms...)
+	if err != nil {
+		return err
+	}
+
+	ctx.DisableCompression()
+	ctx.SetHeader("Content-Digest", value)
+	return nil
+}
+
+// ContentDigest returns the digest field value of the data by the algorithms, or
+// DefaultDigestAlgorithm if none is specified, like "sha-256=:<base64>:".
+func ContentDigest(data []byte, algorithms ...string) (string, error) {
+	if len(algorithms) == 0 {
+		algorithms = []string{DefaultDigestAlgorithm}
+	}
+
+	members := make([]string, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		algorithm = strings.ToLower(algorithm)
+		h, ok := digestAlgorithm(algorithm)
+		if !ok {
+			return "", ErrUnsupportedDigestAlgorithm
+		}
+		members = append(members, algorithm+"=:"+base64.StdEncoding.EncodeToString(digestOf(h, data))+":")
+	}
+
+	return strings.Join(members, ", "), nil
+}
+
+// verifyDigestField verifies the digests of the structured field dictionary against the data.
+func verifyDigestField(value string, data []byte) error {
+	digests := parseDigestField(value)
+
+	names := make([]string, 0, len(digests))
+	for name := range digests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	verified := false
+	for _, name := range names {
+		h, ok := digestAlgorithm(name)
+		if !ok {
+			continue
+		}
+		if !bytes.Equal(digestOf(h, data), digests[name]) {
+			return ErrDigestMismatch
+		}
+		verified = true
+	}
+
+	if !verified {
+		return ErrUnsupportedDigestAlgorithm
+	}
+
+	return nil
+}
+
+// parseDigestField parses the dictionary of the algorithms to the byte sequences of a digest field,
+// the members that are not byte sequences are skipped.
+func parseDigestField(value string) map[string][]byte {
+	digests := make(map[string][]byte)
+
+	for _, member := range strings.Split(value, ",") {
+		name, item, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok {
+			continue
+		}
+		item, _, _ = strings.Cut(item, ";") // parameters are not used by the digest fields
+		item = strings.TrimSpace(item)
+		if len(item) < 2 || item[0] != ':' || item[len(item)-1] != ':' {
+			continue
+		}
+
+		digest, err := base64.StdEncoding.DecodeString(item[1 : len(item)-1])
+		if err != nil {
+			continue
+		}
+		digests[strings.ToLower(strings.TrimSpace(name))] = digest
+	}
+
+	return digests
+}
+
+// digestOf returns the hash of the data.
+func digestOf(h func() hash.Hash, data []byte) []byte {
+	hasher := h()
+	hasher.Write(data)
+	return hasher.Sum(nil)
+}
@@ -0,0 +1,211 @@
+package simple_context
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// testDigestMember returns the member of a digest field of the data by the algorithm.
+func testDigestMember(name string, sum []byte) string {
+	return name + "=:" + base64.StdEncoding.EncodeToString(sum) + ":"
+}
+
+func TestVerifyContentDigest(t *testing.T) {
+	body := "hello"
+	sha256Sum := sha256.Sum256([]byte(body))
+	sha512Sum := sha512.Sum512([]byte(body))
+	sha256Member := testDigestMember("sha-256", sha256Sum[:])
+	sha512Member := testDigestMember("sha-512", sha512Sum[:])
+	wrongSum := sha256.Sum256([]byte("other"))
+
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		wantErr error
+	}{
+		{
+			name:    "content digest",
+			headers: map[string][]string{"Content-Digest": {sha256Member}},
+		},
+		{
+			name:    "repr digest",
+			headers: map[string][]string{"Repr-Digest": {sha512Member}},
+		},
+		{
+			name:    "multiple algorithms",
+			headers: map[string][]string{"Content-Digest": {sha256Member + ", " + sha512Member}},
+		},
+		{
+			name:    "repeated headers",
+			headers: map[string][]string{"Content-Digest": {sha256Member, sha512Member}},
+		},
+		{
+			name:    "parameters",
+			headers: map[string][]string{"Content-Digest": {sha256Member + ";p=1"}},
+		},
+		{
+			name:    "upper case algorithm",
+			headers: map[string][]string{"Content-Digest": {testDigestMember("SHA-256", sha256Sum[:])}},
+		},
+		{
+			name:    "unsupported algorithm ignored",
+			headers: map[string][]string{"Content-Digest": {"unixsum=:AAAA:, " + sha256Member}},
+		},
+		{
+			name:    "mismatch",
+			headers: map[string][]string{"Content-Digest": {testDigestMember("sha-256", wrongSum[:])}},
+			wantErr: ErrDigestMismatch,
+		},
+		{
+			name: "one algorithm mismatch",
+			headers: map[string][]string{
+				"Content-Digest": {sha512Member + ", " + testDigestMember("sha-256", wrongSum[:])},
+			},
+			wantErr: ErrDigestMismatch,
+		},
+		{
+			name: "repr digest mismatch",
+			headers: map[string][]string{
+				"Content-Digest": {sha256Member},
+				"Repr-Digest":    {testDigestMember("sha-256", wrongSum[:])},
+			},
+			wantErr: ErrDigestMismatch,
+		},
+		{
+			name:    "only unsupported algorithms",
+			headers: map[string][]string{"Content-Digest": {"unixsum=:AAAA:"}},
+			wantErr: ErrUnsupportedDigestAlgorithm,
+		},
+		{
+			name:    "not a byte sequence",
+			headers: map[string][]string{"Content-Digest": {"sha-256=abc"}},
+			wantErr: ErrUnsupportedDigestAlgorithm,
+		},
+		{
+			name:    "missing",
+			wantErr: ErrDigestMissing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := newTestCore(http.MethodPost, "/")
+			impl.req.body = []byte(body)
+			for name, values := range tt.headers {
+				for _, value := range values {
+					impl.req.header.Add(name, value)
+				}
+			}
+
+			runTestCore(t, impl, func(c core.Context) {
+				if err := c.(*Context).VerifyContentDigest(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("VerifyContentDigest() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		})
+	}
+}
+
+func TestContentDigest(t *testing.T) {
+	data := []byte("hello")
+	sha256Sum := sha256.Sum256(data)
+	sha512Sum := sha512.Sum512(data)
+
+	tests := []struct {
+		name       string
+		algorithms []string
+		want       string
+		wantErr    error
+	}{
+		{
+			name: "default",
+			want: testDigestMember("sha-256", sha256Sum[:]),
+		},
+		{
+			name:       "multiple algorithms",
+			algorithms: []string{"SHA-512", "sha-256"},
+			want: testDigestMember("sha-512", sha512Sum[:]) + ", " +
+				testDigestMember("sha-256", sha256Sum[:]),
+		},
+		{
+			name:       "unsupported",
+			algorithms: []string{"md5"},
+			wantErr:    ErrUnsupportedDigestAlgorithm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ContentDigest(data, tt.algorithms...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ContentDigest() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ContentDigest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetContentDigestDisablesCompression(t *testing.T) {
+	impl := newTestCore(http.MethodGet, "/")
+	impl.req.header.Set("Accept-Encoding", "gzip")
+	body := make([]byte, 4096)
+
+	runTestCore(t, impl, func(c core.Context) {
+		ctx := c.(*Context)
+		ctx.Compress()
+		if err := ctx.SetContentDigest(body); err != nil {
+			t.Fatalf("SetContentDigest() error = %v", err)
+		}
+		ctx.SetHeader("Content-Type", "text/plain")
+		if _, err := ctx.Write(body); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	})
+
+	if encoding := impl.res.header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Content-Encoding = %q, want none", encoding)
+	}
+	sum := sha256.Sum256(body)
+	want := testDigestMember("sha-256", sum[:])
+	if got := impl.res.header.Get("Content-Digest"); got != want {
+		t.Errorf("Content-Digest = %q, want %q", got, want)
+	}
+}
+
+func TestRegisterDigestAlgorithm(t *testing.T) {
+	t.Cleanup(func() {
+		digestAlgorithmsMu.Lock()
+		delete(digestAlgorithms, "md5")
+		digestAlgorithmsMu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterDigestAlgorithm("MD5", md5.New)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = ContentDigest([]byte("hello"), "md5")
+		}()
+	}
+	wg.Wait()
+
+	sum := md5.Sum([]byte("hello"))
+	got, err := ContentDigest([]byte("hello"), "md5")
+	if want := testDigestMember("md5", sum[:]); err != nil || got != want {
+		t.Errorf("ContentDigest() = %q, %v, want %q", got, err, want)
+	}
+}
@@ -1,11 +1,17 @@
 package simple_context
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-amwk/core"
 )
@@ -19,13 +25,138 @@ type Context struct {
 	index    int
 	isAbort  bool
 	handlers []core.HandlerFunc
+	status   int
+
+	statusWritten    bool
+	statusPending    bool
+	cachedChainRoute string
+
+	startTime time.Time
+	clock     Clock
+	random    io.Reader
+	config    *configSnapshot
+	stdCtx    context.Context
+
+	budgetAllocations []BudgetAllocation
+	capture           *bytes.Buffer
+	streaming         bool
+	complete          bool
+	session           *Session
+	flashes           []Flash
+	flashesLoaded     bool
+	csrfSecretValue   []byte
+	multipartForm     *multipart.Form
+	postForm          url.Values
+	pagination        *paginationState
+	handlerTimings    []HandlerTiming
+	nestedTime        time.Duration
+	deadlineCtx       context.Context
+	deadlineHandled   bool
+	requestID         string
+	traceID           string
+	spanID            string
+	span              Span
+	activeSpan        Span
+	timePolicy        *TimePolicy
+
+	method       string
+	body         []byte
+	encodedBody  []byte
+	bodyRead     bool
+	bodyConsumed bool
+	bodyLimit    int64
+	bodyReplaced bool
+	bodySource   io.Reader
+	bodyTee      io.Writer
+	locale       string
+
+	memoryBudget int64
+	memoryUsed   int64
+
+	depth     int
+	finishers []func()
+	resBuffer *spillBuffer
+	encoder   io.WriteCloser
+	writeMu   sync.Mutex
+
+	compressCoding   string
+	compressDisabled bool
+
+	stats         contextStats
+	leakTimer     *time.Timer
+	profileLabels map[string]string
+	traceCtx      context.Context
 }
 
 func InitContext(ctx *Context, impl core.Context) {
 	ctx.contextImpl = impl
 	ctx.index = -1
 	ctx.isAbort = false
-	ctx.handlers = make([]core.HandlerFunc, 0)
+	ctx.status = 0
+	ctx.statusWritten = false
+	ctx.statusPending = false
+	ctx.clock = DefaultClock
+	ctx.random = nil
+	ctx.config = currentConfig.Load()
+	ctx.stdCtx = nil
+	ctx.budgetAllocations = nil
+	ctx.capture = nil
+	ctx.streaming = false
+	ctx.complete = false
+	ctx.session = nil
+	ctx.flashes = nil
+	ctx.flashesLoaded = false
+	ctx.csrfSecretValue = nil
+	ctx.multipartForm = nil
+	ctx.postForm = nil
+	ctx.pagination = nil
+	ctx.handlerTimings = nil
+	ctx.nestedTime = 0
+	ctx.deadlineCtx = nil
+	ctx.deadlineHandled = false
+	ctx.requestID = ""
+	ctx.traceID = ""
+	ctx.spanID = ""
+	ctx.span = nil
+	ctx.activeSpan = nil
+	ctx.timePolicy = nil
+	ctx.startTime = ctx.clock.Now()
+	ctx.cachedChainRoute = ""
+	ctx.attachDefaultChain()
+	ctx.attachCachedChain()
+	ctx.method = ""
+	ctx.body = nil
+	ctx.encodedBody = nil
+	ctx.bodyRead = false
+	ctx.bodyConsumed = false
+	ctx.bodyLimit = DefaultBodyLimit
+	ctx.bodyReplaced = false
+	ctx.bodySource = nil
+	ctx.bodyTee = nil
+	ctx.locale = ""
+	ctx.memoryBudget = DefaultMemoryBudget
+	ctx.memoryUsed = 0
+	ctx.depth = 0
+	ctx.finishers = nil
+	ctx.resBuffer = nil
+	ctx.encoder = nil
+	ctx.compressCoding = ""
+	ctx.compressDisabled = false
+	ctx.profileLabels = nil
+	ctx.traceCtx = nil
+	ctx.resetStats()
+	ctx.untrackLeak()
+}
+
+// StartTime returns the time when the context was initialized. It carries a monotonic clock
+// reading, so the durations measured from it are not affected by wall clock changes.
+func (ctx *Context) StartTime() time.Time {
+	return ctx.startTime
+}
+
+// Elapsed returns the duration since the context was initialized.
+func (ctx *Context) Elapsed() time.Duration {
+	return ctx.Now().Sub(ctx.startTime)
 }
 
 // Get returns the value associated with the key in the context.
@@ -39,25 +170,79 @@ func (ctx *Context) Set(key string, value any) any {
 	return oldValue
 }
 
-// Abort marks the context as aborted, and subsequent handlers will not be executed.
+// Abort marks the context as aborted, and subsequent handlers will not be executed. If no status
+// code has been written when the handler chain finishes, the response is sent with
+// DefaultAbortStatus.
 func (ctx *Context) Abort() {
+	if !ctx.isAbort {
+		ctx.onFinish(ctx.applyAbortStatus)
+	}
 	ctx.isAbort = true
 }
 
-// IsAbort checks if the context is marked as aborted.
-func (ctx *Context) IsAbort() bool {
+// IsAborted checks if the context is marked as aborted.
+func (ctx *Context) IsAborted() bool {
 	return ctx.isAbort
 }
 
+// IsAbort checks if the context is marked as aborted, it's the same as IsAborted and implements
+// core.Context.
+func (ctx *Context) IsAbort() bool {
+	return ctx.IsAborted()
+}
+
 // Next calls the next handler in the chain.
 func (ctx *Context) Next() {
+	endTask := ctx.startTraceTask()
+	defer endTask()
+
+	defer ctx.enterChain()()
+
+	if ctx.index == -1 {
+		ctx.startServerSpan()
+		if !ctx.admit() {
+			return
+		}
+	}
+
 	ctx.index++
-	for ctx.index < len(ctx.handlers) && !ctx.isAbort {
-		ctx.handlers[ctx.index](ctx)
+	ctx.runChain()
+}
+
+// enterChain increases the depth of the chain execution, and returns a function to decrease it
+// that finishes the request when the outermost execution returns.
+func (ctx *Context) enterChain() func() {
+	ctx.depth++
+	return func() {
+		ctx.depth--
+		if ctx.depth == 0 {
+			ctx.finish()
+		}
+	}
+}
+
+// runChain executes the handlers from the current index until the chain ends or is aborted.
+func (ctx *Context) runChain() {
+	for ctx.index < len(ctx.handlers) && !ctx.isAbort && !ctx.checkDeadline() {
+		ctx.runHandler(ctx.handlers[ctx.index])
 		ctx.index++
 	}
 }
 
+// runHandler executes the handler with the instrumentation of the context.
+func (ctx *Context) runHandler(handler core.HandlerFunc) {
+	ctx.recordHandler()
+	ctx.withHandlerTiming(handler, func() {
+		ctx.withHandlerSpan(handler, func() {
+			ctx.withProfileLabels(func() {
+				ctx.withTraceRegion(handler, func() {
+					handler(ctx)
+				})
+			})
+		})
+	})
+}
+
 // Use adds handlers to the context, which will be executed in the order they are added.
 func (ctx *Context) Use(handlers ...core.HandlerFunc) {
 	ctx.handlers = append(ctx.handlers, handlers...)
@@ -69,9 +254,50 @@ func (ctx *Context) BasicAuth() (string, string, bool) {
 	return ctx.Request().BasicAuth()
 }
 
-// Body returns the request body as a byte slice.
+// Body returns the request body as a byte slice. The body encoded with a content coding in the
+// Content-Encoding header, like gzip, is decompressed transparently. The body is read once and
+// cached, so it can be read by the middlewares and the handlers in turn.
 func (ctx *Context) Body() ([]byte, error) {
-	return ctx.Request().Body()
+	if ctx.bodyRead {
+		return ctx.body, nil
+	}
+	if ctx.bodyConsumed {
+		return nil, ErrBodyConsumed
+	}
+	if ctx.bodySource != nil {
+		return ctx.readBodySource()
+	}
+
+	if err := ctx.checkBodyLimit(ctx.ContentLength(), nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.checkMemory(ctx.ContentLength()); err != nil {
+		return nil, err
+	}
+
+	ctx.limitRawBody()
+	encoded, err := ctx.Request().Body()
+	if err := ctx.checkBodyLimit(int64(len(encoded)), err); err != nil {
+		return nil, err
+	}
+	if ctx.bodyTee != nil {
+		if _, err := ctx.bodyTee.Write(encoded); err != nil {
+			return nil, err
+		}
+	}
+	body, err := ctx.decodeBody(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.reserveMemory(int64(len(body))); err != nil {
+		return nil, err
+	}
+	ctx.body = body
+	ctx.encodedBody = encoded
+	ctx.bodyRead = true
+	ctx.recordRead(len(body))
+
+	return body, nil
 }
 
 // ClientIP returns the IP address of the client making the request.
@@ -84,8 +310,16 @@ func (ctx *Context) ClientIP() string {
 	return ctx.Request().ClientIP()
 }
 
-// ContentLength returns the length of the request body in bytes.
+// ContentLength returns the length of the request body in bytes. It's the length of the replaced
+// body if the body is replaced by SetBodyBytes, or -1 if it's replaced by SetBody and not read yet.
 func (ctx *Context) ContentLength() int64 {
+	if ctx.bodyReplaced {
+		if ctx.bodySource != nil {
+			return -1
+		}
+		return int64(len(ctx.body))
+	}
+
 	return ctx.Request().ContentLength()
 }
 
@@ -120,9 +354,17 @@ func (ctx *Context) Headers() http.Header {
 	return ctx.Request().Headers()
 }
 
-// Method returns the HTTP method of the request (e.g., GET, POST).
+// Method returns the HTTP method of the request (e.g., GET, POST). It reports the overridden
+// method if the method override is enabled and the request overrides it.
 func (ctx *Context) Method() string {
-	return ctx.Request().Method()
+	if ctx.method == "" {
+		ctx.method = ctx.overriddenMethod()
+		if ctx.method == "" {
+			ctx.method = ctx.Request().Method()
+		}
+	}
+
+	return ctx.method
 }
 
 // Protocol returns the HTTP protocol version of the request (e.g., HTTP/1.1).
@@ -145,6 +387,21 @@ func (ctx *Context) Resource() string {
 	return ctx.Request().Resource()
 }
 
+// Origin returns the Origin header of the request.
+func (ctx *Context) Origin() string {
+	return ctx.Header("Origin")
+}
+
+// Referer returns the Referer header of the request.
+func (ctx *Context) Referer() string {
+	return ctx.Header("Referer")
+}
+
+// UserAgent returns the User-Agent header of the request.
+func (ctx *Context) UserAgent() string {
+	return ctx.Header("User-Agent")
+}
+
 // Query retrieves a query parameter value by name from the request.
 func (ctx *Context) Query(key string) string {
 	return ctx.Request().Queries().Get(key)
@@ -155,6 +412,27 @@ func (ctx *Context) QueryValues(key string) []string {
 	return ctx.Request().Queries()[key]
 }
 
+// QueryArray retrieves all values for a query parameter by name from the request. If splitComma is
+// true, the values are also split by commas, so "?id=1,2,3" and "?id=1&id=2&id=3" are the same,
+// and the empty items are dropped.
+func (ctx *Context) QueryArray(key string, splitComma bool) []string {
+	values := ctx.QueryValues(key)
+	if !splitComma {
+		return values
+	}
+
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
+
 // Queries returns all query parameters from the request as a url.Values.
 func (ctx *Context) Queries() url.Values {
 	return ctx.Request().Queries()
@@ -180,16 +458,57 @@ func (ctx *Context) DelHeader(key string) {
 	ctx.Response().DelHeader(key)
 }
 
-// Status sets the HTTP status code for the response and returns an error if it fails.
+// Status sets the HTTP status code for the response and returns an error if it fails. If the
+// compression of the response is pending, the status code is sent with the headers when the body
+// is written or the handler chain finishes, so the headers set after it still decide the
+// compression.
 func (ctx *Context) Status(code int) error {
 	if code < 100 || code > 999 {
 		return errors.New("invalid status code")
 	}
 
-	return ctx.Response().Status(code)
+	if ctx.compressCoding != "" && code >= http.StatusOK {
+		if !ctx.statusPending {
+			ctx.onFinish(ctx.flushPendingStatus)
+		}
+		ctx.statusPending = true
+	} else if err := ctx.Response().Status(code); err != nil {
+		return err
+	}
+	ctx.status = code
+	ctx.statusWritten = true
+
+	return nil
 }
 
-// Write writes data to the response body.
+// Write writes data to the response body. Without the stream mode, the write completes the
+// response, and it fails with ErrResponseComplete if the response has been completed by a renderer
+// or a previous write.
 func (ctx *Context) Write(data []byte) (int, error) {
-	return ctx.Response().Write(data)
+	if ctx.complete && !ctx.streaming {
+		return 0, ErrResponseComplete
+	}
+	if !ctx.streaming {
+		ctx.complete = true
+	}
+
+	if err := ctx.flushHeaders(-1); err != nil {
+		return 0, err
+	}
+	if ctx.status == 0 {
+		ctx.status = http.StatusOK
+	}
+
+	var n int
+	var err error
+	if ctx.encoder != nil {
+		n, err = ctx.encoder.Write(data)
+	} else {
+		n, err = ctx.writeBody(data)
+	}
+	if ctx.capture != nil && n > 0 {
+		ctx.capture.Write(data[:n])
+	}
+	ctx.recordWrite(n)
+	return n, err
 }
@@ -0,0 +1,156 @@
+package simple_context
+
+import (
+	"net/http"
+	"time"
+)
+
+// CookieDefaultsConfig is the default attributes of the cookies set through the contexts.
+type CookieDefaultsConfig struct {
+	// Path is the default Path attribute, the default is "/".
+	Path string
+	// Domain is the default Domain attribute.
+	Domain string
+	// Secure is the default Secure attribute.
+	Secure bool
+	// HTTPOnly is the default HttpOnly attribute.
+	HTTPOnly bool
+	// SameSite is the default SameSite attribute.
+	SameSite http.SameSite
+}
+
+// CookieDefaults is the default attributes of the cookies set through the contexts, so the
+// security posture of the cookies is configured once. It's secure by default.
+var CookieDefaults = CookieDefaultsConfig{
+	Path:     "/",
+	Secure:   true,
+	HTTPOnly: true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// CookieOption configures a cookie set by SetCookieValue.
+type CookieOption func(cookie *http.Cookie)
+
+// WithPath sets the Path attribute of the cookie.
+func WithPath(path string) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.Path = path
+	}
+}
+
+// WithDomain sets the Domain attribute of the cookie.
+func WithDomain(domain string) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.Domain = domain
+	}
+}
+
+// WithMaxAge sets the Max-Age attribute of the cookie, and the Expires attribute for the clients
+// that don't support Max-Age. A zero or negative duration deletes the cookie.
+func WithMaxAge(d time.Duration) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.MaxAge = int(d / time.Second)
+		if cookie.MaxAge <= 0 {
+			cookie.MaxAge = -1
+		}
+	}
+}
+
+// WithExpires sets the Expires attribute of the cookie.
+func WithExpires(t time.Time) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.Expires = t
+	}
+}
+
+// WithSecure sets the Secure attribute of the cookie.
+func WithSecure(secure bool) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.Secure = secure
+	}
+}
+
+// WithHTTPOnly sets the HttpOnly attribute of the cookie.
+func WithHTTPOnly(httpOnly bool) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.HttpOnly = httpOnly
+	}
+}
+
+// WithSameSite sets the SameSite attribute of the cookie. The Secure attribute is also set for
+// SameSite=None, as browsers reject the cookie without it.
+func WithSameSite(sameSite http.SameSite) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.SameSite = sameSite
+		if sameSite == http.SameSiteNoneMode {
+			cookie.Secure = true
+		}
+	}
+}
+
+// SetCookie adds the Set-Cookie header of the cookie to the response. The zero-value attributes
+// of the cookie are filled by CookieDefaults, the Secure and HttpOnly attributes can only be
+// turned off by SetCookieValue with options. The invalid cookies are dropped with an error.
+func (ctx *Context) SetCookie(cookie *http.Cookie) error {
+	c := *cookie
+	defaults := CookieDefaults
+	if c.Path == "" {
+		c.Path = defaults.Path
+	}
+	if c.Domain == "" {
+		c.Domain = defaults.Domain
+	}
+	if c.SameSite == 0 {
+		c.SameSite = defaults.SameSite
+	}
+	c.Secure = c.Secure || defaults.Secure || c.SameSite == http.SameSiteNoneMode
+	c.HttpOnly = c.HttpOnly || defaults.HTTPOnly
+
+	return ctx.addCookie(&c)
+}
+
+// addCookie adds the Set-Cookie header of the cookie to the response.
+func (ctx *Context) addCookie(cookie *http.Cookie) error {
+	if err := cookie.Valid(); err != nil {
+		return err
+	}
+
+	ctx.AddHeader("Set-Cookie", cookie.String())
+	return nil
+}
+
+// SetCookieValue sets the cookie of the name and value with the options. The attributes are
+// CookieDefaults unless they're overridden by options, and the Expires attribute is derived from
+// Max-Age by the clock of the request.
+func (ctx *Context) SetCookieValue(name, value string, opts ...CookieOption) error {
+	defaults := CookieDefaults
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     defaults.Path,
+		Domain:   defaults.Domain,
+		Secure:   defaults.Secure,
+		HttpOnly: defaults.HTTPOnly,
+		SameSite: defaults.SameSite,
+	}
+	for _, opt := range opts {
+		opt(cookie)
+	}
+
+	if cookie.Expires.IsZero() {
+		if cookie.MaxAge > 0 {
+			cookie.Expires = ctx.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+		} else if cookie.MaxAge < 0 {
+			cookie.Expires = time.Unix(0, 0)
+		}
+	}
+
+	return ctx.addCookie(cookie)
+}
+
+// DeleteCookie deletes the cookie of the name on the client. The options must match the Path and
+// Domain attributes that the cookie was set with.
+func (ctx *Context) DeleteCookie(name string, opts ...CookieOption) error {
+	opts = append(opts, WithMaxAge(-1))
+	return ctx.SetCookieValue(name, "", opts...)
+}
@@ -0,0 +1,184 @@
+package simple_context
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrCSRFTokenMissing is returned by VerifyCSRF if the request carries no CSRF token or the client
+// has no CSRF secret.
+var ErrCSRFTokenMissing = errors.New("csrf token missing")
+
+// ErrCSRFTokenInvalid is returned by VerifyCSRF if the CSRF token of the request doesn't match the
+// secret of the client.
+var ErrCSRFTokenInvalid = errors.New("csrf token invalid")
+
+// CSRFConfig is the configuration of the CSRF protection.
+type CSRFConfig struct {
+	// HeaderName is the name of the request header carrying the token, the default is
+	// X-CSRF-Token.
+	HeaderName string
+	// FormField is the name of the form field carrying the token, the default is _csrf.
+	FormField string
+	// CookieName is the name of the cookie of the secret if no session store is set, the default
+	// is _csrf.
+	CookieName string
+	// Identity returns the identity of the client, like the user ID, which is signed with the
+	// secret in the cookie mode. A cookie signed for another identity is rejected.
+	Identity func(ctx *Context) string
+}
+
+// CSRF is the CSRF protection configuration of the contexts. The per-client secret is kept in the
+// session (synchronizer token pattern) if a session store is configured, or in a cookie signed by
+// the cookie keys (signed double-submit cookie pattern) otherwise. The cookie mode requires the
+// cookie keys set by SetCookieKeys, and the signed cookie is bound to the client only by Identity.
+// Without Identity, an attacker controlling a sibling subdomain can plant its own signed cookie
+// and forge the matching tokens, so the session store should be used in that case.
+var CSRF = CSRFConfig{}
+
+const (
+	// csrfSessionKey is the reserved session key of the CSRF secret.
+	csrfSessionKey = "_csrf_secret"
+	// csrfSecretBytes is the count of the random bytes of a CSRF secret.
+	csrfSecretBytes = 32
+)
+
+// CSRFToken returns a CSRF token of the client for embedding in forms or passing to scripts. The
+// secret of the client is created on the first call, and the token is masked by a random pad on
+// every call so it's not compressible (BREACH). It returns an empty string if the secret can't be
+// stored. It must be called before the response status and body are written on the first request
+// of a client.
+func (ctx *Context) CSRFToken() string {
+	secret := ctx.csrfSecret()
+	if secret == nil {
+		var err error
+		secret, err = secureRandomBytes(csrfSecretBytes)
+		if err != nil || ctx.storeCSRFSecret(secret) != nil {
+			return ""
+		}
+	}
+
+	pad, err := secureRandomBytes(len(secret))
+	if err != nil {
+		return ""
+	}
+
+	token := make([]byte, 2*len(secret))
+	copy(token, pad)
+	subtle.XORBytes(token[len(secret):], pad, secret)
+
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// VerifyCSRF verifies the CSRF token of the request, carried by the header or the form field,
+// against the secret of the client. The safe methods (GET, HEAD, OPTIONS, and TRACE) are exempted
+// by the original method of the request, so the method override can't skip the verification.
+func (ctx *Context) VerifyCSRF() error {
+	switch ctx.OriginalMethod() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return nil
+	}
+
+	secret := ctx.csrfSecret()
+	if secret == nil {
+		return ErrCSRFTokenMissing
+	}
+
+	config := CSRF
+	headerName := config.HeaderName
+	if headerName == "" {
+		headerName = "X-CSRF-Token"
+	}
+	encoded := ctx.Header(headerName)
+	if encoded == "" {
+		fieldName := config.FormField
+		if fieldName == "" {
+			fieldName = "_csrf"
+		}
+		encoded = ctx.PostForm(fieldName)
+	}
+	if encoded == "" {
+		return ErrCSRFTokenMissing
+	}
+
+	token, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(token) != 2*len(secret) {
+		return ErrCSRFTokenInvalid
+	}
+	unmasked := make([]byte, len(secret))
+	subtle.XORBytes(unmasked, token[:len(secret)], token[len(secret):])
+	if subtle.ConstantTimeCompare(unmasked, secret) != 1 {
+		return ErrCSRFTokenInvalid
+	}
+
+	return nil
+}
+
+// csrfCookieName returns the name of the cookie of the CSRF secret.
+func (config CSRFConfig) csrfCookieName() string {
+	if config.CookieName == "" {
+		return "_csrf"
+	}
+
+	return config.CookieName
+}
+
+// identity returns the identity of the client bound to the secret cookie.
+func (config CSRFConfig) identity(ctx *Context) string {
+	if config.Identity == nil {
+		return ""
+	}
+
+	return config.Identity(ctx)
+}
+
+// csrfSecret returns the CSRF secret of the client, or nil if it has none.
+func (ctx *Context) csrfSecret() []byte {
+	if ctx.csrfSecretValue != nil {
+		return ctx.csrfSecretValue
+	}
+
+	var encoded string
+	if Sessions.Store != nil {
+		value, _ := ctx.Session().Get(csrfSessionKey)
+		encoded, _ = value.(string)
+	} else if value, err := ctx.SignedCookie(CSRF.csrfCookieName()); err == nil {
+		var identity string
+		encoded, identity, _ = strings.Cut(value, "|")
+		if identity != CSRF.identity(ctx) {
+			return nil
+		}
+	}
+
+	secret, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(secret) != csrfSecretBytes {
+		return nil
+	}
+	ctx.csrfSecretValue = secret
+
+	return secret
+}
+
+// storeCSRFSecret stores the CSRF secret of the client in the session, or in the signed cookie with
+// the identity of the client.
+func (ctx *Context) storeCSRFSecret(secret []byte) error {
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+
+	if Sessions.Store != nil {
+		sess := ctx.Session()
+		sess.Set(csrfSessionKey, encoded)
+		if err := sess.Save(); err != nil {
+			return err
+		}
+	} else if err := ctx.SetSignedCookie(
+		CSRF.csrfCookieName(), encoded+"|"+CSRF.identity(ctx), WithHTTPOnly(true),
+	); err != nil {
+		return err
+	}
+	ctx.csrfSecretValue = secret
+
+	return nil
+}
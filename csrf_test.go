@@ -0,0 +1,211 @@
+package simple_context
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// setCSRFTestConfig sets the cookie mode of the CSRF protection with the identity read from the
+// X-User header for the test.
+func setCSRFTestConfig(t *testing.T) {
+	t.Helper()
+
+	setTestValue(t, &cookieKeys, [][]byte{[]byte("csrf-test-key")})
+	setTestValue(t, &Sessions, SessionConfig{})
+	setTestValue(t, &CSRF, CSRFConfig{
+		Identity: func(ctx *Context) string {
+			return ctx.Header("X-User")
+		},
+	})
+}
+
+// issueCSRFTestToken issues a CSRF token for the user, and returns the token and the Cookie header
+// of the secret.
+func issueCSRFTestToken(t *testing.T, user string) (string, string) {
+	t.Helper()
+
+	impl := newTestCore(http.MethodGet, "/")
+	impl.req.header.Set("X-User", user)
+
+	var token string
+	res := runTestCore(t, impl, func(c core.Context) {
+		token = c.(*Context).CSRFToken()
+	})
+	if token == "" {
+		t.Fatal("CSRFToken() = empty token")
+	}
+
+	return token, testCookieHeader(res)
+}
+
+func TestVerifyCSRF(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		override string
+		user     string
+		cookie   bool
+		header   func(token string) string
+		form     func(token string) string
+		wantErr  error
+	}{
+		{
+			name:   "header token",
+			method: http.MethodPost,
+			user:   "alice",
+			cookie: true,
+			header: func(token string) string { return token },
+		},
+		{
+			name:   "form token",
+			method: http.MethodPost,
+			user:   "alice",
+			cookie: true,
+			form:   func(token string) string { return token },
+		},
+		{
+			name:   "safe method",
+			method: http.MethodGet,
+			user:   "alice",
+		},
+		{
+			name:     "overridden to a safe method",
+			method:   http.MethodPost,
+			override: http.MethodGet,
+			user:     "alice",
+			cookie:   true,
+			wantErr:  ErrCSRFTokenMissing,
+		},
+		{
+			name:    "missing token",
+			method:  http.MethodPost,
+			user:    "alice",
+			cookie:  true,
+			wantErr: ErrCSRFTokenMissing,
+		},
+		{
+			name:    "missing cookie",
+			method:  http.MethodPost,
+			user:    "alice",
+			header:  func(token string) string { return token },
+			wantErr: ErrCSRFTokenMissing,
+		},
+		{
+			name:    "cookie of another identity",
+			method:  http.MethodPost,
+			user:    "mallory",
+			cookie:  true,
+			header:  func(token string) string { return token },
+			wantErr: ErrCSRFTokenMissing,
+		},
+		{
+			name:   "tampered token",
+			method: http.MethodPut,
+			user:   "alice",
+			cookie: true,
+			header: func(token string) string {
+				raw, _ := base64.RawURLEncoding.DecodeString(token)
+				raw[len(raw)-1] ^= 1
+				return base64.RawURLEncoding.EncodeToString(raw)
+			},
+			wantErr: ErrCSRFTokenInvalid,
+		},
+		{
+			name:    "malformed token",
+			method:  http.MethodDelete,
+			user:    "alice",
+			cookie:  true,
+			header:  func(string) string { return "not a token" },
+			wantErr: ErrCSRFTokenInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setCSRFTestConfig(t)
+			setTestValue(t, &MethodOverride, MethodOverrideConfig{
+				Enabled: true,
+				Methods: []string{http.MethodGet},
+			})
+			token, cookie := issueCSRFTestToken(t, "alice")
+
+			impl := newTestCore(tt.method, "/")
+			impl.req.header.Set("X-User", tt.user)
+			if tt.cookie {
+				impl.req.header.Set("Cookie", cookie)
+			}
+			if tt.override != "" {
+				impl.req.header.Set("X-HTTP-Method-Override", tt.override)
+			}
+			if tt.header != nil {
+				impl.req.header.Set("X-CSRF-Token", tt.header(token))
+			}
+			if tt.form != nil {
+				impl.req.header.Set("Content-Type", "application/x-www-form-urlencoded")
+				impl.req.body = []byte(url.Values{"_csrf": {tt.form(token)}}.Encode())
+			}
+
+			runTestCore(t, impl, func(c core.Context) {
+				if err := c.(*Context).VerifyCSRF(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("VerifyCSRF() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		})
+	}
+}
+
+func TestCSRFTokenMasked(t *testing.T) {
+	setCSRFTestConfig(t)
+
+	impl := newTestCore(http.MethodGet, "/")
+	runTestCore(t, impl, func(c core.Context) {
+		ctx := c.(*Context)
+		first, second := ctx.CSRFToken(), ctx.CSRFToken()
+		if first == second {
+			t.Errorf("CSRFToken() returns the same token %q twice", first)
+		}
+	})
+
+	if cookies := impl.res.header.Values("Set-Cookie"); len(cookies) != 1 {
+		t.Errorf("Set-Cookie = %q, want one cookie of the secret", cookies)
+	}
+}
+
+func TestCSRFSessionStore(t *testing.T) {
+	setCSRFTestConfig(t)
+	store := NewMemorySessionStore(0)
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	setTestValue(t, &Sessions, SessionConfig{Store: store})
+
+	token, cookie := issueCSRFTestToken(t, "alice")
+
+	tests := []struct {
+		name    string
+		cookie  string
+		wantErr error
+	}{
+		{name: "same session", cookie: cookie},
+		{name: "no session", wantErr: ErrCSRFTokenMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := newTestCore(http.MethodPost, "/")
+			impl.req.header.Set("Cookie", tt.cookie)
+			impl.req.header.Set("X-CSRF-Token", token)
+
+			runTestCore(t, impl, func(c core.Context) {
+				if err := c.(*Context).VerifyCSRF(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("VerifyCSRF() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		})
+	}
+}
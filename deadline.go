@@ -0,0 +1,58 @@
+package simple_context
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DeadlineExceededHandler responds to the requests exceeding their deadlines set by SetDeadline,
+// if no status code has been written. It responds with 504 Gateway Timeout by default.
+var DeadlineExceededHandler = func(ctx *Context) {
+	_ = ctx.Status(http.StatusGatewayTimeout)
+}
+
+// SetDeadline sets the deadline of the request. The standard context of the request is derived
+// with the deadline, so it's cancelled when the deadline is exceeded, and the handlers after that
+// are not executed. The reads of the body of the net/http request fail after the deadline. Besides
+// the body reads, the deadline is cooperative: a running handler is never interrupted, so the
+// handlers that block must watch StdContext to release the connection in time. The response is
+// written by DeadlineExceededHandler if the chain finishes without writing a status code. The
+// earlier deadline wins if it's called multiple times.
+func (ctx *Context) SetDeadline(t time.Time) {
+	deadlineCtx, cancel := context.WithDeadline(ctx.StdContext(), t)
+	ctx.SetStdContext(deadlineCtx)
+	if w := ctx.RawResponseWriter(); w != nil {
+		deadline, _ := deadlineCtx.Deadline()
+		_ = http.NewResponseController(w).SetReadDeadline(deadline)
+	}
+
+	if ctx.deadlineCtx == nil {
+		ctx.onFinish(func() {
+			ctx.checkDeadline()
+		})
+	}
+	ctx.deadlineCtx = deadlineCtx
+	ctx.onFinish(cancel)
+}
+
+// checkDeadline aborts the context if its deadline is exceeded, records the error on the span,
+// and responds by DeadlineExceededHandler if no status code has been written. It returns whether
+// the deadline is exceeded.
+func (ctx *Context) checkDeadline() bool {
+	if ctx.deadlineCtx == nil || !errors.Is(ctx.deadlineCtx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+
+	if !ctx.deadlineHandled {
+		ctx.deadlineHandled = true
+		ctx.RecordError(ctx.deadlineCtx.Err())
+		ctx.Abort()
+		if ctx.status == 0 && DeadlineExceededHandler != nil {
+			DeadlineExceededHandler(ctx)
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+package simple_context
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ErrDecompressedBodyTooLarge is returned by Body if the decompressed request body exceeds
+// MaxDecompressedSize.
+var ErrDecompressedBodyTooLarge = errors.New("decompressed body too large")
+
+// ErrUnsupportedContentEncoding is returned by Body if the request body is encoded with a content
+// coding that has no registered decoder.
+var ErrUnsupportedContentEncoding = errors.New("unsupported content encoding")
+
+// DecoderFactory creates a decoder of a content coding that reads the encoded data from the
+// reader.
+type DecoderFactory func(r io.Reader) (io.ReadCloser, error)
+
+// MaxDecompressedSize is the maximum size in bytes of the decompressed request bodies, to protect
+// against decompression bombs. Zero means unlimited.
+var MaxDecompressedSize int64 = 10 << 20
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]DecoderFactory{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"x-gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": func(r io.Reader) (io.ReadCloser, error) {
+			return zlib.NewReader(r)
+		},
+	}
+)
+
+// RegisterDecoder registers the decoder factory of the content coding, like "br" or "zstd", for
+// the transparent decompression of request bodies.
+func RegisterDecoder(coding string, factory DecoderFactory) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[strings.ToLower(coding)] = factory
+}
+
+// contentCodings returns the content codings of the request body by the Content-Encoding header,
+// in the order they are applied.
+func (ctx *Context) contentCodings() []string {
+	codings := make([]string, 0)
+	for _, value := range ctx.HeaderValues("Content-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+
+	return codings
+}
+
+// decodeBody decodes the request body by the Content-Encoding header of the request. The codings
+// are applied in the reverse order they are listed.
+func (ctx *Context) decodeBody(body []byte) ([]byte, error) {
+	codings := ctx.contentCodings()
+	if len(codings) == 0 {
+		return body, nil
+	}
+
+	reader, closeDecoders, err := decodeStream(bytes.NewReader(body), codings)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDecoders()
+
+	return io.ReadAll(reader)
+}
+
+// decodeStream returns the reader decoding the stream by the content codings, and the function
+// closing the decoders. The decoded data is limited by MaxDecompressedSize, reading beyond it
+// returns ErrDecompressedBodyTooLarge.
+func decodeStream(r io.Reader, codings []string) (io.Reader, func(), error) {
+	closers := make([]io.Closer, 0, len(codings))
+	closeDecoders := func() {
+		for _, c := range closers {
+			c.Close()
+		}
+	}
+
+	for i := len(codings) - 1; i >= 0; i-- {
+		decodersMu.RLock()
+		factory, ok := decoders[codings[i]]
+		decodersMu.RUnlock()
+		if !ok {
+			closeDecoders()
+			return nil, nil, ErrUnsupportedContentEncoding
+		}
+
+		decoder, err := factory(r)
+		if err != nil {
+			closeDecoders()
+			return nil, nil, err
+		}
+		closers = append(closers, decoder)
+		r = decoder
+	}
+
+	if MaxDecompressedSize > 0 && len(codings) > 0 {
+		r = &decompressLimitReader{r: r, remaining: MaxDecompressedSize}
+	}
+
+	return r, closeDecoders, nil
+}
+
+// decompressLimitReader reads the decompressed data up to the limit, and returns
+// ErrDecompressedBodyTooLarge if the data exceeds it.
+type decompressLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// Read reads the decompressed data.
+func (l *decompressLimitReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrDecompressedBodyTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrDecompressedBodyTooLarge
+	}
+
+	return n, err
+}
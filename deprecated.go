@@ -0,0 +1,70 @@
+package simple_context
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+)
+
+// Deprecation describes a deprecated alias of the context API and its canonical name.
+type Deprecation struct {
+	// Alias is the deprecated name.
+	Alias string
+	// Canonical is the name that replaces the alias.
+	Canonical string
+}
+
+// DeprecatedUsage is a usage of a deprecated alias found by CheckDeprecated.
+type DeprecatedUsage struct {
+	Deprecation
+	// Position is the source position of the usage.
+	Position token.Position
+}
+
+// deprecations are the deprecated aliases of the context API, keyed by the alias. IsAbort is not
+// listed, it's required by core.Context and can't be removed.
+var deprecations = map[string]string{}
+
+// Deprecations returns the deprecated aliases of the context API, sorted by the alias.
+func Deprecations() []Deprecation {
+	list := make([]Deprecation, 0, len(deprecations))
+	for alias, canonical := range deprecations {
+		list = append(list, Deprecation{Alias: alias, Canonical: canonical})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Alias < list[j].Alias
+	})
+
+	return list
+}
+
+// CheckDeprecated reports the usages of the deprecated aliases in the Go source, like a vet
+// check, so the frameworks embedding the context can migrate to the canonical names. The source
+// is parsed without type information, so it reports all selectors with the name of an alias.
+func CheckDeprecated(filename string, src any) ([]DeprecatedUsage, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]DeprecatedUsage, 0)
+	ast.Inspect(file, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if canonical, ok := deprecations[sel.Sel.Name]; ok {
+			usages = append(usages, DeprecatedUsage{
+				Deprecation: Deprecation{Alias: sel.Sel.Name, Canonical: canonical},
+				Position:    fset.Position(sel.Sel.Pos()),
+			})
+		}
+
+		return true
+	})
+
+	return usages, nil
+}
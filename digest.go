@@ -0,0 +1,308 @@
+package simple_context
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DigestConfig is the configuration of the HTTP Digest authentication.
+type DigestConfig struct {
+	// Algorithm is the algorithm of the challenges, MD5 or SHA-256, the default is MD5 as it's
+	// supported by most clients. The HA1 returned by the lookup functions must be hashed by it.
+	Algorithm string
+	// NonceTTL is the lifetime of the nonces, the default is 5 minutes.
+	NonceTTL time.Duration
+}
+
+// Digest is the HTTP Digest authentication configuration of the contexts.
+var Digest = DigestConfig{}
+
+var (
+	digestNonceMu sync.Mutex
+	// digestNonceKey is the key signing the nonces of the process, the nonces are signed so
+	// they're verified without a store.
+	digestNonceKey []byte
+
+	digestNonceCountsMu sync.Mutex
+	// digestNonceCounts are the last nonce counts of the used nonces, to reject the replayed
+	// requests. They're kept until the nonces expire.
+	digestNonceCounts = make(map[string]digestNonceCount)
+)
+
+// digestNonceCount is the last nonce count of a used nonce.
+type digestNonceCount struct {
+	count   uint64
+	expires time.Time
+}
+
+// algorithm returns the algorithm of the challenges.
+func (config DigestConfig) algorithm() string {
+	if strings.EqualFold(config.Algorithm, "SHA-256") {
+		return "SHA-256"
+	}
+
+	return "MD5"
+}
+
+// nonceTTL returns the lifetime of the nonces.
+func (config DigestConfig) nonceTTL() time.Duration {
+	if config.NonceTTL <= 0 {
+		return 5 * time.Minute
+	}
+
+	return config.NonceTTL
+}
+
+// DigestAuth validates the Digest Authorization header (RFC 7616) of the request in the realm, and
+// returns the username if the credentials are valid. The lookup function returns the HA1 of the
+// user, that is the hex hash of "username:realm:password" by the algorithm of Digest. It sets the
+// WWW-Authenticate challenge on failure, so the caller only needs to respond 401. The nonce count
+// of each nonce must increase, and a nonce without qop can be used once, so the replayed requests
+// are rejected with a stale challenge. The nonce counts are kept in the memory of the process, so
+// the replays across the instances of a deployment are not detected.
+func (ctx *Context) DigestAuth(realm string, lookup func(user string) (ha1 string, ok bool)) (string, bool) {
+	config := Digest
+	params, ok := parseDigestAuthorization(ctx.Header("Authorization"))
+	if !ok {
+		_ = ctx.DigestChallenge(realm, false)
+		return "", false
+	}
+
+	username := params["username"]
+	algorithm := params["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+	if username == "" || params["realm"] != realm || !strings.EqualFold(algorithm, config.algorithm()) {
+		_ = ctx.DigestChallenge(realm, false)
+		return "", false
+	}
+
+	uri := params["uri"]
+	uriPath, _, _ := strings.Cut(uri, "?")
+	if uriPath != ctx.Path() {
+		_ = ctx.DigestChallenge(realm, false)
+		return "", false
+	}
+
+	nonce := params["nonce"]
+	valid, stale := ctx.verifyDigestNonce(nonce, realm, config.nonceTTL())
+	if !valid {
+		_ = ctx.DigestChallenge(realm, stale)
+		return "", false
+	}
+
+	ha1, ok := lookup(username)
+	if !ok {
+		_ = ctx.DigestChallenge(realm, false)
+		return "", false
+	}
+
+	newHash := md5.New
+	if config.algorithm() == "SHA-256" {
+		newHash = sha256.New
+	}
+	ha2 := digestHash(newHash, ctx.OriginalMethod()+":"+uri)
+
+	var expected string
+	switch qop := params["qop"]; qop {
+	case "auth":
+		expected = digestHash(newHash, strings.Join([]string{
+			ha1, nonce, params["nc"], params["cnonce"], qop, ha2,
+		}, ":"))
+	case "":
+		expected = digestHash(newHash, ha1+":"+nonce+":"+ha2)
+	default:
+		_ = ctx.DigestChallenge(realm, false)
+		return "", false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(params["response"]))) != 1 {
+		_ = ctx.DigestChallenge(realm, false)
+		return "", false
+	}
+
+	count := uint64(1)
+	if params["qop"] != "" {
+		nc, err := strconv.ParseUint(params["nc"], 16, 32)
+		if err != nil || nc == 0 {
+			_ = ctx.DigestChallenge(realm, false)
+			return "", false
+		}
+		count = nc
+	}
+	if !ctx.useDigestNonce(nonce, count, config.nonceTTL()) {
+		_ = ctx.DigestChallenge(realm, true)
+		return "", false
+	}
+
+	return username, true
+}
+
+// DigestChallenge sets the Digest WWW-Authenticate challenge of the realm with a new nonce. The
+// stale flag tells the client that its credentials are valid but the nonce is expired, so it can
+// retry without prompting the user. It returns an error if the nonce key can't be generated.
+func (ctx *Context) DigestChallenge(realm string, stale bool) error {
+	nonce, err := ctx.newDigestNonce(realm)
+	if err != nil {
+		return err
+	}
+
+	challenge := "Digest realm=" + strconv.Quote(realm) +
+		`, qop="auth", algorithm=` + Digest.algorithm() +
+		", nonce=" + strconv.Quote(nonce)
+	if stale {
+		challenge += ", stale=true"
+	}
+
+	ctx.SetHeader("WWW-Authenticate", challenge)
+	return nil
+}
+
+// newDigestNonce returns a nonce of the realm, which is the current time signed by the nonce key.
+func (ctx *Context) newDigestNonce(realm string) (string, error) {
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(ctx.Now().UnixNano()))
+
+	mac, err := digestNonceMAC(timestamp[:], realm)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(append(timestamp[:], mac...)), nil
+}
+
+// verifyDigestNonce checks if the nonce is signed by the nonce key for the realm, and if it's
+// expired.
+func (ctx *Context) verifyDigestNonce(nonce, realm string, ttl time.Duration) (valid, stale bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(raw) <= 8 {
+		return false, false
+	}
+
+	mac, err := digestNonceMAC(raw[:8], realm)
+	if err != nil || !hmac.Equal(raw[8:], mac) {
+		return false, false
+	}
+
+	issued := time.Unix(0, int64(binary.BigEndian.Uint64(raw[:8])))
+	if ctx.Now().Sub(issued) > ttl {
+		return false, true
+	}
+
+	return true, false
+}
+
+// useDigestNonce records the nonce count of the nonce, and returns false if it's not greater than
+// the last count of the nonce. The records of the expired nonces are removed.
+func (ctx *Context) useDigestNonce(nonce string, count uint64, ttl time.Duration) bool {
+	digestNonceCountsMu.Lock()
+	defer digestNonceCountsMu.Unlock()
+
+	now := ctx.Now()
+	for n, c := range digestNonceCounts {
+		if !now.Before(c.expires) {
+			delete(digestNonceCounts, n)
+		}
+	}
+
+	if last, ok := digestNonceCounts[nonce]; ok && count <= last.count {
+		return false
+	}
+	digestNonceCounts[nonce] = digestNonceCount{count: count, expires: now.Add(ttl)}
+
+	return true
+}
+
+// digestNonceMAC returns the signature of the nonce timestamp for the realm. The nonce key is read
+// from crypto/rand.Reader on the first call, and it's read again on the next call if the reading
+// fails.
+func digestNonceMAC(timestamp []byte, realm string) ([]byte, error) {
+	digestNonceMu.Lock()
+	if digestNonceKey == nil {
+		key, err := secureRandomBytes(32)
+		if err != nil {
+			digestNonceMu.Unlock()
+			return nil, err
+		}
+		digestNonceKey = key
+	}
+	key := digestNonceKey
+	digestNonceMu.Unlock()
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(timestamp)
+	mac.Write([]byte(realm))
+	return mac.Sum(nil)[:16], nil
+}
+
+// digestHash returns the hex hash of the data.
+func digestHash(newHash func() hash.Hash, data string) string {
+	h := newHash()
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parseDigestAuthorization returns the parameters of the Digest Authorization header value.
+func parseDigestAuthorization(header string) (map[string]string, bool) {
+	credentials, ok := parseAuthorization(header, "Digest")
+	if !ok {
+		return nil, false
+	}
+
+	params := parseAuthParams(credentials)
+	return params, len(params) > 0
+}
+
+// parseAuthParams parses the comma-separated auth parameters of an Authorization header, the
+// values may be tokens or quoted strings. The parameter names are lowercased.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return params
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return params
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			s = s[min(i+1, len(s)):]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+
+		params[name] = value
+	}
+}
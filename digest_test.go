@@ -0,0 +1,247 @@
+package simple_context
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-amwk/core"
+)
+
+const (
+	digestTestRealm    = "test"
+	digestTestUser     = "alice"
+	digestTestPassword = "secret"
+)
+
+// digestTestNonce returns the nonce of a challenge set by the context at the time.
+func digestTestNonce(t *testing.T) string {
+	t.Helper()
+
+	res := runTestContext(t, func(c core.Context) {
+		if err := c.(*Context).DigestChallenge(digestTestRealm, false); err != nil {
+			t.Fatalf("DigestChallenge() error = %v", err)
+		}
+	})
+
+	params, ok := parseDigestAuthorization(res.header.Get("WWW-Authenticate"))
+	if !ok || params["nonce"] == "" {
+		t.Fatalf("WWW-Authenticate = %q, want a challenge", res.header.Get("WWW-Authenticate"))
+	}
+
+	return params["nonce"]
+}
+
+// digestTestCredentials is the Authorization header parameters computed by a client.
+type digestTestCredentials struct {
+	password string
+	realm    string
+	uri      string
+	nonce    string
+	nc       int
+	qop      string
+}
+
+// header returns the Digest Authorization header of the credentials of the GET request.
+func (c digestTestCredentials) header() string {
+	ha1 := digestHash(md5.New, digestTestUser+":"+c.realm+":"+c.password)
+	ha2 := digestHash(md5.New, http.MethodGet+":"+c.uri)
+	nc := fmt.Sprintf("%08x", c.nc)
+
+	response := digestHash(md5.New, ha1+":"+c.nonce+":"+ha2)
+	if c.qop != "" {
+		response = digestHash(md5.New, strings.Join([]string{ha1, c.nonce, nc, "abc", c.qop, ha2}, ":"))
+	}
+
+	header := fmt.Sprintf(`Digest username=%q, realm=%q, uri=%q, nonce=%q, response=%q`,
+		digestTestUser, c.realm, c.uri, c.nonce, response)
+	if c.qop != "" {
+		header += fmt.Sprintf(`, qop=%s, nc=%s, cnonce="abc"`, c.qop, nc)
+	}
+
+	return header
+}
+
+// digestTestAuth runs DigestAuth with the Authorization header, and returns whether it succeeds
+// and the challenge of the response.
+func digestTestAuth(t *testing.T, authorization string) (bool, string) {
+	t.Helper()
+
+	impl := newTestCore(http.MethodGet, "/private")
+	impl.req.header.Set("Authorization", authorization)
+
+	var ok bool
+	res := runTestCore(t, impl, func(c core.Context) {
+		var user string
+		user, ok = c.(*Context).DigestAuth(digestTestRealm, func(user string) (string, bool) {
+			if user != digestTestUser {
+				return "", false
+			}
+			return digestHash(md5.New, user+":"+digestTestRealm+":"+digestTestPassword), true
+		})
+		if ok && user != digestTestUser {
+			t.Errorf("DigestAuth() user = %q, want %q", user, digestTestUser)
+		}
+	})
+
+	return ok, res.header.Get("WWW-Authenticate")
+}
+
+func TestDigestAuth(t *testing.T) {
+	valid := digestTestCredentials{
+		password: digestTestPassword,
+		realm:    digestTestRealm,
+		uri:      "/private?page=1",
+		nc:       1,
+		qop:      "auth",
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(c *digestTestCredentials)
+		advance   time.Duration
+		header    func(header string) string
+		wantOK    bool
+		wantStale bool
+	}{
+		{name: "valid", wantOK: true},
+		{name: "without qop", modify: func(c *digestTestCredentials) { c.qop = "" }, wantOK: true},
+		{name: "wrong password", modify: func(c *digestTestCredentials) { c.password = "guess" }},
+		{name: "wrong realm", modify: func(c *digestTestCredentials) { c.realm = "other" }},
+		{name: "wrong uri", modify: func(c *digestTestCredentials) { c.uri = "/public" }},
+		{name: "zero nonce count", modify: func(c *digestTestCredentials) { c.nc = 0 }},
+		{
+			name:   "forged nonce",
+			modify: func(c *digestTestCredentials) { c.nonce = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA" },
+		},
+		{name: "expired nonce", advance: 10 * time.Minute, wantStale: true},
+		{
+			name: "unsupported algorithm",
+			header: func(header string) string {
+				return header + ", algorithm=SHA-512"
+			},
+		},
+		{
+			name: "not digest",
+			header: func(string) string {
+				return "Basic YWxpY2U6c2VjcmV0"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			setTestValue(t, &DefaultClock, Clock(ClockFunc(func() time.Time { return now })))
+			setTestValue(t, &digestNonceCounts, make(map[string]digestNonceCount))
+			setTestValue(t, &Digest, DigestConfig{})
+
+			credentials := valid
+			credentials.nonce = digestTestNonce(t)
+			if tt.modify != nil {
+				tt.modify(&credentials)
+			}
+			header := credentials.header()
+			if tt.header != nil {
+				header = tt.header(header)
+			}
+			now = now.Add(tt.advance)
+
+			ok, challenge := digestTestAuth(t, header)
+			if ok != tt.wantOK {
+				t.Errorf("DigestAuth() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok && !strings.HasPrefix(challenge, "Digest ") {
+				t.Errorf("WWW-Authenticate = %q, want a Digest challenge", challenge)
+			}
+			if stale := strings.Contains(challenge, "stale=true"); stale != tt.wantStale {
+				t.Errorf("WWW-Authenticate = %q, want stale %v", challenge, tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestDigestAuthReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		qop    string
+		counts []int
+		wantOK []bool
+	}{
+		{name: "increasing count", qop: "auth", counts: []int{1, 3}, wantOK: []bool{true, true}},
+		{name: "replayed count", qop: "auth", counts: []int{1, 1}, wantOK: []bool{true, false}},
+		{name: "decreasing count", qop: "auth", counts: []int{3, 2}, wantOK: []bool{true, false}},
+		{name: "nonce without qop", qop: "", counts: []int{1, 1}, wantOK: []bool{true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestValue(t, &digestNonceCounts, make(map[string]digestNonceCount))
+			setTestValue(t, &Digest, DigestConfig{})
+
+			credentials := digestTestCredentials{
+				password: digestTestPassword,
+				realm:    digestTestRealm,
+				uri:      "/private",
+				nonce:    digestTestNonce(t),
+				qop:      tt.qop,
+			}
+			for i, count := range tt.counts {
+				credentials.nc = count
+				ok, challenge := digestTestAuth(t, credentials.header())
+				if ok != tt.wantOK[i] {
+					t.Errorf("request %d: DigestAuth() ok = %v, want %v", i, ok, tt.wantOK[i])
+				}
+				if !ok && !strings.Contains(challenge, "stale=true") {
+					t.Errorf("request %d: WWW-Authenticate = %q, want a stale challenge", i, challenge)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAuthParams(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]string
+	}{
+		{
+			name: "tokens and quoted strings",
+			in:   `username="alice", qop=auth, NC=00000001`,
+			want: map[string]string{"username": "alice", "qop": "auth", "nc": "00000001"},
+		},
+		{
+			name: "escaped quote and comma",
+			in:   `realm="a \"b\", c", uri="/x"`,
+			want: map[string]string{"realm": `a "b", c`, "uri": "/x"},
+		},
+		{
+			name: "unterminated quote",
+			in:   `realm="abc`,
+			want: map[string]string{"realm": "abc"},
+		},
+		{
+			name: "no value",
+			in:   `realm`,
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAuthParams(tt.in)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseAuthParams(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("parseAuthParams(%q)[%q] = %q, want %q", tt.in, name, got[name], value)
+				}
+			}
+		})
+	}
+}
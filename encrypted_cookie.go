@@ -0,0 +1,85 @@
+package simple_context
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrInvalidEncryptedCookie is returned by EncryptedCookie if the cookie can't be decrypted by any
+// of the encryption keys.
+var ErrInvalidEncryptedCookie = errors.New("invalid encrypted cookie")
+
+var cookieCiphers []cipher.AEAD
+
+// SetCookieEncryptionKeys sets the AES keys (16, 24, or 32 bytes) of the encrypted cookies. The
+// first key encrypts the new cookies, and all keys decrypt the cookies, so the keys can be rotated
+// by prepending a new key. It should be called before serving requests.
+func SetCookieEncryptionKeys(keys ...[]byte) error {
+	ciphers := make([]cipher.AEAD, 0, len(keys))
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return err
+		}
+		ciphers = append(ciphers, aead)
+	}
+
+	cookieCiphers = ciphers
+	return nil
+}
+
+// SetEncryptedCookie sets the cookie with the value encrypted and authenticated by AES-GCM, so the
+// clients can neither read nor tamper the value. The cookie name is authenticated with the value,
+// so an encrypted value can't be moved to another cookie. The nonces are read from
+// crypto/rand.Reader regardless of the entropy source of the context, so they never repeat.
+func (ctx *Context) SetEncryptedCookie(name, value string, opts ...CookieOption) error {
+	if len(cookieCiphers) == 0 {
+		return ErrNoCookieKeys
+	}
+	aead := cookieCiphers[0]
+
+	nonce, err := secureRandomBytes(aead.NonceSize())
+	if err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+
+	return ctx.SetCookieValue(name, base64.RawURLEncoding.EncodeToString(sealed), opts...)
+}
+
+// EncryptedCookie returns the decrypted value of the encrypted cookie. It returns
+// http.ErrNoCookie if the cookie is absent, or ErrInvalidEncryptedCookie if it can't be
+// decrypted.
+func (ctx *Context) EncryptedCookie(name string) (string, error) {
+	if len(cookieCiphers) == 0 {
+		return "", ErrNoCookieKeys
+	}
+
+	cookie, err := ctx.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return "", ErrInvalidEncryptedCookie
+	}
+
+	for _, aead := range cookieCiphers {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if value, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+			return string(value), nil
+		}
+	}
+
+	return "", ErrInvalidEncryptedCookie
+}
@@ -0,0 +1,156 @@
+package simple_context
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// setEncryptionTestKeys sets the cookie encryption keys for the test.
+func setEncryptionTestKeys(t *testing.T, keys ...[]byte) {
+	t.Helper()
+
+	setTestValue(t, &cookieCiphers, cookieCiphers)
+	if err := SetCookieEncryptionKeys(keys...); err != nil {
+		t.Fatalf("SetCookieEncryptionKeys() error = %v", err)
+	}
+}
+
+// setEncryptedTestCookie sets the encrypted cookie by the keys, and returns the Cookie header
+// sending it back.
+func setEncryptedTestCookie(t *testing.T, name, value string, keys ...[]byte) string {
+	t.Helper()
+
+	setEncryptionTestKeys(t, keys...)
+	res := runTestContext(t, func(c core.Context) {
+		if err := c.(*Context).SetEncryptedCookie(name, value); err != nil {
+			t.Fatalf("SetEncryptedCookie() error = %v", err)
+		}
+	})
+
+	return testCookieHeader(res)
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 16)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	tests := []struct {
+		name    string
+		setName string
+		sealKey []byte
+		keys    [][]byte
+		cookie  func(header string) string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			sealKey: newKey,
+			keys:    [][]byte{newKey},
+			want:    "alice",
+		},
+		{
+			name:    "rotated key",
+			sealKey: oldKey,
+			keys:    [][]byte{newKey, oldKey},
+			want:    "alice",
+		},
+		{
+			name:    "unknown key",
+			sealKey: oldKey,
+			keys:    [][]byte{newKey},
+			wantErr: ErrInvalidEncryptedCookie,
+		},
+		{
+			name:    "tampered",
+			sealKey: newKey,
+			keys:    [][]byte{newKey},
+			cookie: func(header string) string {
+				name, value, _ := strings.Cut(header, "=")
+				sealed, _ := base64.RawURLEncoding.DecodeString(value)
+				sealed[len(sealed)-1] ^= 1
+				return name + "=" + base64.RawURLEncoding.EncodeToString(sealed)
+			},
+			wantErr: ErrInvalidEncryptedCookie,
+		},
+		{
+			name:    "moved from another name",
+			setName: "other",
+			sealKey: newKey,
+			keys:    [][]byte{newKey},
+			cookie: func(header string) string {
+				return "user" + strings.TrimPrefix(header, "other")
+			},
+			wantErr: ErrInvalidEncryptedCookie,
+		},
+		{
+			name:    "truncated",
+			sealKey: newKey,
+			keys:    [][]byte{newKey},
+			cookie: func(string) string {
+				return "user=AAAA"
+			},
+			wantErr: ErrInvalidEncryptedCookie,
+		},
+		{
+			name:    "missing",
+			sealKey: newKey,
+			keys:    [][]byte{newKey},
+			cookie: func(string) string {
+				return ""
+			},
+			wantErr: http.ErrNoCookie,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setName := tt.setName
+			if setName == "" {
+				setName = "user"
+			}
+			header := setEncryptedTestCookie(t, setName, "alice", tt.sealKey)
+			if tt.cookie != nil {
+				header = tt.cookie(header)
+			}
+			setEncryptionTestKeys(t, tt.keys...)
+
+			impl := newTestCore(http.MethodGet, "/")
+			impl.req.header.Set("Cookie", header)
+			runTestCore(t, impl, func(c core.Context) {
+				got, err := c.(*Context).EncryptedCookie("user")
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("EncryptedCookie() error = %v, want %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("EncryptedCookie() = %q, want %q", got, tt.want)
+				}
+			})
+		})
+	}
+}
+
+func TestEncryptedCookieUniqueNonces(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, 32)
+	setTestValue(t, &DefaultRandom, NewSeededRandom(1))
+
+	first := setEncryptedTestCookie(t, "user", "alice", key)
+	second := setEncryptedTestCookie(t, "user", "alice", key)
+	if first == second {
+		t.Errorf("the cookies encrypted with a seeded random share the nonce: %q", first)
+	}
+}
+
+func TestSetCookieEncryptionKeysInvalidKey(t *testing.T) {
+	setTestValue(t, &cookieCiphers, cookieCiphers)
+
+	if err := SetCookieEncryptionKeys([]byte("short")); err == nil {
+		t.Error("SetCookieEncryptionKeys() error = nil, want an error of the key size")
+	}
+}
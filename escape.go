@@ -0,0 +1,52 @@
+package simple_context
+
+import (
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// JSEscape escapes the string so it can be embedded in a JavaScript string literal, including
+// literals inside HTML script elements.
+func JSEscape(s string) string {
+	return template.JSEscapeString(s)
+}
+
+// CSSEscape escapes the string so it can be embedded in a CSS string or identifier. All characters
+// other than ASCII letters and digits are replaced with their hexadecimal escapes.
+func CSSEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r > 0x7f {
+			sb.WriteRune(r)
+			continue
+		}
+
+		sb.WriteByte('\\')
+		sb.WriteString(strconv.FormatInt(int64(r), 16))
+		sb.WriteByte(' ')
+	}
+
+	return sb.String()
+}
+
+// URLQueryEscape escapes the string so it can be placed inside a URL query.
+func URLQueryEscape(s string) string {
+	return url.QueryEscape(s)
+}
+
+// JSEscape escapes the string so it can be embedded in a JavaScript string literal.
+func (ctx *Context) JSEscape(s string) string {
+	return JSEscape(s)
+}
+
+// CSSEscape escapes the string so it can be embedded in a CSS string or identifier.
+func (ctx *Context) CSSEscape(s string) string {
+	return CSSEscape(s)
+}
+
+// URLQueryEscape escapes the string so it can be placed inside a URL query.
+func (ctx *Context) URLQueryEscape(s string) string {
+	return URLQueryEscape(s)
+}
@@ -0,0 +1,108 @@
+package simple_context
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Flash is a message persisted across a redirect.
+type Flash struct {
+	// Kind is the kind of the message, like "success" or "error".
+	Kind string `json:"kind"`
+	// Message is the content of the message.
+	Message string `json:"message"`
+}
+
+const (
+	// flashSessionKey is the reserved session key of the flash messages.
+	flashSessionKey = "_flashes"
+	// flashCookieName is the name of the cookie of the flash messages if no session store is set.
+	flashCookieName = "_flash"
+)
+
+// Flash adds a message that is shown on the next request, like after a POST-redirect-GET. The
+// messages are kept in the session if a session store is configured, or in a cookie otherwise. It
+// must be called before the response status and body are written.
+func (ctx *Context) Flash(kind, message string) error {
+	flashes := ctx.peekFlashes()
+	flashes = append(flashes, Flash{Kind: kind, Message: message})
+	return ctx.storeFlashes(flashes)
+}
+
+// Flashes returns the flash messages added by the previous requests, and clears them so they're
+// shown only once. It must be called before the response status and body are written.
+func (ctx *Context) Flashes() []Flash {
+	flashes := ctx.peekFlashes()
+	if len(flashes) > 0 {
+		_ = ctx.storeFlashes(nil)
+	}
+
+	return flashes
+}
+
+// peekFlashes returns the flash messages without clearing them.
+func (ctx *Context) peekFlashes() []Flash {
+	if ctx.flashesLoaded {
+		return append([]Flash(nil), ctx.flashes...)
+	}
+	ctx.flashesLoaded = true
+
+	var data []byte
+
+	if Sessions.Store != nil {
+		value, ok := ctx.Session().Get(flashSessionKey)
+		if !ok {
+			return nil
+		}
+		if s, ok := value.(string); ok {
+			data = []byte(s)
+		}
+	} else {
+		cookie, err := ctx.Cookie(flashCookieName)
+		if err != nil {
+			return nil
+		}
+		data, err = base64.RawURLEncoding.DecodeString(cookie.Value)
+		if err != nil {
+			return nil
+		}
+	}
+
+	var flashes []Flash
+	if err := json.Unmarshal(data, &flashes); err != nil {
+		return nil
+	}
+	ctx.flashes = flashes
+
+	return append([]Flash(nil), flashes...)
+}
+
+// storeFlashes replaces the flash messages, the messages are cleared if flashes is empty.
+func (ctx *Context) storeFlashes(flashes []Flash) error {
+	ctx.flashes = flashes
+	ctx.flashesLoaded = true
+
+	if Sessions.Store != nil {
+		sess := ctx.Session()
+		if len(flashes) == 0 {
+			sess.Delete(flashSessionKey)
+		} else {
+			data, err := json.Marshal(flashes)
+			if err != nil {
+				return err
+			}
+			sess.Set(flashSessionKey, string(data))
+		}
+		return sess.Save()
+	}
+
+	if len(flashes) == 0 {
+		return ctx.DeleteCookie(flashCookieName)
+	}
+
+	data, err := json.Marshal(flashes)
+	if err != nil {
+		return err
+	}
+	return ctx.SetCookieValue(flashCookieName, base64.RawURLEncoding.EncodeToString(data))
+}
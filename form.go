@@ -0,0 +1,98 @@
+package simple_context
+
+import (
+	"net/url"
+	"strings"
+)
+
+// postFormValues returns the parsed form of the application/x-www-form-urlencoded or
+// multipart/form-data body of the request. The form is parsed once and cached, and it's empty if
+// the body is not a form or can't be parsed.
+func (ctx *Context) postFormValues() url.Values {
+	if ctx.postForm != nil {
+		return ctx.postForm
+	}
+
+	form := make(url.Values)
+	switch ctx.ContentType() {
+	case "application/x-www-form-urlencoded":
+		if body, err := ctx.Body(); err == nil {
+			if values, err := url.ParseQuery(string(body)); err == nil {
+				form = values
+			}
+		}
+	case "multipart/form-data":
+		if multipartForm, err := ctx.MultipartForm(DefaultMultipartMemory); err == nil {
+			for key, values := range multipartForm.Value {
+				form[key] = values
+			}
+		}
+	}
+	ctx.postForm = form
+
+	return form
+}
+
+// PostForm returns the first value of the key in the urlencoded or multipart form body of the
+// request.
+func (ctx *Context) PostForm(key string) string {
+	return ctx.postFormValues().Get(key)
+}
+
+// PostFormValues returns all values of the key in the urlencoded or multipart form body of the
+// request.
+func (ctx *Context) PostFormValues(key string) []string {
+	return ctx.postFormValues()[key]
+}
+
+// DefaultPostForm returns the first value of the key in the form body of the request, or the
+// default value if the form has no such key.
+func (ctx *Context) DefaultPostForm(key, defaultValue string) string {
+	if values, ok := ctx.postFormValues()[key]; ok && len(values) > 0 {
+		return values[0]
+	}
+
+	return defaultValue
+}
+
+// FormValue returns the first value of the key in the form body of the request, or in the query
+// parameters if the form has no such key.
+func (ctx *Context) FormValue(key string) string {
+	if values := ctx.postFormValues()[key]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ctx.Query(key)
+}
+
+// QueryMap returns the query parameters of the key in the bracket form, like "filter[status]=a"
+// and "filter[type]=b" for the key "filter", as a map of the bracketed names to the first values.
+func (ctx *Context) QueryMap(key string) map[string]string {
+	return bracketMap(ctx.Queries(), key)
+}
+
+// PostFormMap returns the form values of the key in the bracket form, like "filter[status]=a" and
+// "filter[type]=b" for the key "filter", as a map of the bracketed names to the first values.
+func (ctx *Context) PostFormMap(key string) map[string]string {
+	return bracketMap(ctx.postFormValues(), key)
+}
+
+// bracketMap collects the values of the keys in the form of "key[name]" into a map of the names to
+// the first values. The nested brackets are not collected.
+func bracketMap(values url.Values, key string) map[string]string {
+	result := make(map[string]string)
+
+	for k, v := range values {
+		name, ok := strings.CutPrefix(k, key+"[")
+		if !ok || len(v) == 0 {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, "]")
+		if !ok || strings.ContainsAny(name, "[]") {
+			continue
+		}
+		result[name] = v[0]
+	}
+
+	return result
+}
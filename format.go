@@ -0,0 +1,215 @@
+package simple_context
+
+import (
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localeFormat describes how numbers, currencies, and dates are rendered in a locale.
+type localeFormat struct {
+	decimal       string
+	group         string
+	symbolAfter   bool
+	symbolSpacing bool
+	dateLayout    string
+}
+
+var defaultLocaleFormat = localeFormat{
+	decimal:    ".",
+	group:      ",",
+	dateLayout: "Jan 2, 2006",
+}
+
+// localeFormats are the formats of the supported locales, keyed by the lowercase primary language
+// subtag or the full tag if it differs from the primary language.
+var localeFormats = map[string]localeFormat{
+	"en":    defaultLocaleFormat,
+	"en-gb": {decimal: ".", group: ",", dateLayout: "2 Jan 2006"},
+	"de":    {decimal: ",", group: ".", symbolAfter: true, symbolSpacing: true, dateLayout: "02.01.2006"},
+	"fr":    {decimal: ",", group: " ", symbolAfter: true, symbolSpacing: true, dateLayout: "02/01/2006"},
+	"es":    {decimal: ",", group: ".", symbolAfter: true, symbolSpacing: true, dateLayout: "02/01/2006"},
+	"it":    {decimal: ",", group: ".", symbolAfter: true, symbolSpacing: true, dateLayout: "02/01/2006"},
+	"pt":    {decimal: ",", group: ".", symbolSpacing: true, dateLayout: "02/01/2006"},
+	"nl":    {decimal: ",", group: ".", symbolSpacing: true, dateLayout: "02-01-2006"},
+	"ru":    {decimal: ",", group: " ", symbolAfter: true, symbolSpacing: true, dateLayout: "02.01.2006"},
+	"ja":    {decimal: ".", group: ",", dateLayout: "2006/01/02"},
+	"zh":    {decimal: ".", group: ",", dateLayout: "2006/01/02"},
+	"ko":    {decimal: ".", group: ",", dateLayout: "2006. 01. 02."},
+}
+
+// currencySymbols are the symbols of the common currencies, other currencies are rendered with
+// their ISO 4217 codes.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"KRW": "₩",
+	"INR": "₹",
+	"RUB": "₽",
+	"BRL": "R$",
+}
+
+// currencyDecimals are the minor unit digits of the currencies that don't use two.
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// lookupLocaleFormat returns the format of the locale, falling back to its primary language and
+// then to English.
+func lookupLocaleFormat(locale string) localeFormat {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if format, ok := localeFormats[locale]; ok {
+		return format
+	}
+
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		if format, ok := localeFormats[locale[:i]]; ok {
+			return format
+		}
+	}
+
+	return defaultLocaleFormat
+}
+
+// formatNumber formats the number with the given count of decimals in the locale format.
+func (f localeFormat) formatNumber(value float64, decimals int) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	s := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	var sb strings.Builder
+	if value < 0 && strings.Trim(s, "0.") != "" {
+		sb.WriteByte('-')
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteString(f.group)
+		}
+		sb.WriteRune(c)
+	}
+	if fracPart != "" {
+		sb.WriteString(f.decimal)
+		sb.WriteString(fracPart)
+	}
+
+	return sb.String()
+}
+
+// formatCurrency formats the amount of the currency in the locale format.
+func (f localeFormat) formatCurrency(amount float64, currency string) string {
+	currency = strings.ToUpper(currency)
+	decimals, ok := currencyDecimals[currency]
+	if !ok {
+		decimals = 2
+	}
+
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency
+	}
+
+	number := f.formatNumber(amount, decimals)
+	negative := strings.HasPrefix(number, "-")
+	number = strings.TrimPrefix(number, "-")
+
+	separator := ""
+	if f.symbolSpacing || len(symbol) == 3 && symbol == currency {
+		separator = " "
+	}
+
+	var s string
+	if f.symbolAfter {
+		s = number + separator + symbol
+	} else {
+		s = symbol + separator + number
+	}
+	if negative {
+		s = "-" + s
+	}
+
+	return s
+}
+
+// FormatNumber formats the number with the given count of decimals using the locale of the
+// current request.
+func (ctx *Context) FormatNumber(value float64, decimals int) string {
+	return lookupLocaleFormat(ctx.Locale()).formatNumber(value, decimals)
+}
+
+// FormatCurrency formats the amount of the currency (an ISO 4217 code like "USD") using the
+// locale of the current request.
+func (ctx *Context) FormatCurrency(amount float64, currency string) string {
+	return lookupLocaleFormat(ctx.Locale()).formatCurrency(amount, currency)
+}
+
+// FormatDate formats the date using the locale of the current request.
+func (ctx *Context) FormatDate(t time.Time) string {
+	return t.Format(lookupLocaleFormat(ctx.Locale()).dateLayout)
+}
+
+// TemplateFuncStubs returns the stubs of the template functions of TemplateFuncs, html/template
+// requires the functions to be defined when a template is parsed, so the templates rendered by
+// HTML must be parsed with them, like template.New("page").Funcs(TemplateFuncStubs()).Parse(src).
+// The stubs are replaced by the functions bound to the request when the template is executed, and
+// they must not be called.
+func TemplateFuncStubs() template.FuncMap {
+	return new(Context).TemplateFuncs()
+}
+
+// TemplateFuncs returns the template functions bound to the current request, for rendering
+// templates with html/template. The templates using them must be parsed with TemplateFuncStubs.
+func (ctx *Context) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"formatNumber":   ctx.FormatNumber,
+		"formatCurrency": ctx.FormatCurrency,
+		"formatDate":     ctx.FormatDate,
+		"t":              ctx.T,
+		"csrfToken":      ctx.CSRFToken,
+		"sanitizeHTML": func(input string) template.HTML {
+			return ctx.SanitizeHTML(input, nil)
+		},
+		"jsEscape":       JSEscape,
+		"cssEscape":      CSSEscape,
+		"urlQueryEscape": URLQueryEscape,
+	}
+}
+
+// FormatMeta returns the formatted representations of the number, currency amount, and date
+// using the locale of the current request, for the meta fields of JSON responses.
+func (ctx *Context) FormatMeta(values map[string]any) map[string]string {
+	meta := make(map[string]string, len(values))
+	for key, value := range values {
+		switch v := value.(type) {
+		case int:
+			meta[key] = ctx.FormatNumber(float64(v), 0)
+		case int64:
+			meta[key] = ctx.FormatNumber(float64(v), 0)
+		case float64:
+			meta[key] = ctx.FormatNumber(v, 2)
+		case time.Time:
+			meta[key] = ctx.FormatDate(v)
+		case Money:
+			meta[key] = ctx.FormatCurrency(v.Amount, v.Currency)
+		}
+	}
+
+	return meta
+}
+
+// Money is an amount of a currency, formatted with the currency symbol by FormatMeta.
+type Money struct {
+	Amount   float64
+	Currency string
+}
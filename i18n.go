@@ -0,0 +1,117 @@
+package simple_context
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LanguageTag is a language range in the Accept-Language header with its quality.
+type LanguageTag struct {
+	// Tag is the language tag, like "en-US", or "*".
+	Tag string
+	// Quality is the quality value of the tag, between 0 and 1.
+	Quality float64
+}
+
+// AcceptLanguages returns the languages in the Accept-Language header of the request, ordered by
+// the quality descending. The languages with zero quality are excluded.
+func (ctx *Context) AcceptLanguages() []LanguageTag {
+	tags := make([]LanguageTag, 0)
+	for _, value := range ParseQualityValues(strings.Join(ctx.HeaderValues("Accept-Language"), ",")) {
+		if value.Quality > 0 {
+			tags = append(tags, LanguageTag{Tag: value.Value, Quality: value.Quality})
+		}
+	}
+
+	return tags
+}
+
+// NegotiateLanguage returns the supported language that best matches the Accept-Language header
+// of the request. A language range matches a supported language if they are equal, or if the
+// range matches it after its trailing subtags are removed (e.g. "en-US" matches "en"). It returns
+// the first supported language if none of them matches, or an empty string if none is supported.
+func (ctx *Context) NegotiateLanguage(supported ...string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	ctx.AddVary("Accept-Language")
+	for _, tag := range ctx.AcceptLanguages() {
+		if tag.Tag == "*" {
+			return supported[0]
+		}
+
+		for lang := tag.Tag; lang != ""; {
+			for _, s := range supported {
+				if strings.EqualFold(strings.ReplaceAll(s, "_", "-"), lang) {
+					return s
+				}
+			}
+
+			i := strings.LastIndexByte(lang, '-')
+			if i < 0 {
+				break
+			}
+			lang = lang[:i]
+		}
+	}
+
+	return supported[0]
+}
+
+// SetLocale sets the locale of the current request, which is used by the locale-aware helpers of
+// the context.
+func (ctx *Context) SetLocale(locale string) {
+	ctx.locale = locale
+}
+
+// Locale returns the locale of the current request. It's the locale set by SetLocale, or the
+// preferred language of the Accept-Language header if it's not set.
+func (ctx *Context) Locale() string {
+	if ctx.locale != "" {
+		return ctx.locale
+	}
+
+	for _, tag := range ctx.AcceptLanguages() {
+		if tag.Tag != "*" {
+			return tag.Tag
+		}
+	}
+
+	return ""
+}
+
+// Translator translates messages into locales.
+type Translator interface {
+	// Translate returns the message of the key in the locale, formatted with the arguments.
+	Translate(locale, key string, args ...any) string
+}
+
+// TranslatorFunc is an adapter to allow the use of ordinary functions as translators.
+type TranslatorFunc func(locale, key string, args ...any) string
+
+// Translate calls f(locale, key, args...).
+func (f TranslatorFunc) Translate(locale, key string, args ...any) string {
+	return f(locale, key, args...)
+}
+
+var translator Translator
+
+// SetTranslator sets the translator used by the contexts. It should be called before serving
+// requests.
+func SetTranslator(t Translator) {
+	translator = t
+}
+
+// T translates the message of the key into the locale of the current request. It returns the key
+// formatted with the arguments if no translator is set.
+func (ctx *Context) T(key string, args ...any) string {
+	if translator == nil {
+		if len(args) == 0 {
+			return key
+		}
+		return fmt.Sprintf(key, args...)
+	}
+
+	return translator.Translate(ctx.Locale(), key, args...)
+}
@@ -0,0 +1,304 @@
+package simple_context
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyKeyNotFound is returned by the idempotency stores if no response is recorded for
+// the key.
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+// IdempotencyRecord is a response recorded for an idempotency key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request of the response, a request reusing the key must have the
+	// same fingerprint.
+	Fingerprint string
+	// Response is the recorded response.
+	Response CapturedResponse
+}
+
+// IdempotencyStore stores the recorded responses by the idempotency keys.
+type IdempotencyStore interface {
+	// Load returns the record of the key, or ErrIdempotencyKeyNotFound if it doesn't exist.
+	Load(ctx context.Context, key string) (IdempotencyRecord, error)
+	// Save stores the record of the key, which expires after the TTL.
+	Save(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+}
+
+// IdempotencyLocker is implemented by the idempotency stores that can lock the keys, so the
+// concurrent requests with the same key are rejected instead of executed twice.
+type IdempotencyLocker interface {
+	// Lock locks the key for the TTL, and returns false if it's already locked.
+	Lock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Unlock unlocks the key.
+	Unlock(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig is the configuration of the idempotent requests.
+type IdempotencyConfig struct {
+	// Store is the store of the recorded responses, Idempotent does nothing if it's nil.
+	Store IdempotencyStore
+	// Header is the name of the request header carrying the key, the default is Idempotency-Key.
+	Header string
+	// TTL is the duration that the responses are kept, the default is 24 hours.
+	TTL time.Duration
+	// LockTTL is the longest duration that a key is locked by an in-flight request, the default is
+	// 1 minute.
+	LockTTL time.Duration
+	// Methods are the methods of the idempotent requests, the default is POST and PATCH.
+	Methods []string
+	// Headers are the names of the response headers recorded and replayed, the default is
+	// Content-Type, Content-Language, Location, ETag, Last-Modified, and Cache-Control.
+	Headers []string
+	// Scope returns the principal of the request, like the user ID, that the keys are scoped to,
+	// so a client can never replay the response recorded for another one. The default is the
+	// Authorization header of the request.
+	Scope func(ctx *Context) string
+}
+
+// Idempotency is the idempotent request configuration of the contexts.
+var Idempotency = IdempotencyConfig{}
+
+// header returns the name of the header carrying the key.
+func (config IdempotencyConfig) header() string {
+	if config.Header == "" {
+		return "Idempotency-Key"
+	}
+
+	return config.Header
+}
+
+// storeKey returns the key of the record in the store, which is the idempotency key scoped to the
+// principal of the request.
+func (config IdempotencyConfig) storeKey(ctx *Context, key string) string {
+	scope := ctx.Header("Authorization")
+	if config.Scope != nil {
+		scope = config.Scope(ctx)
+	}
+
+	sum := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(sum[:]) + ":" + key
+}
+
+// IdempotencyKey returns the idempotency key of the request.
+func (ctx *Context) IdempotencyKey() (string, bool) {
+	key := strings.TrimSpace(ctx.Header(Idempotency.header()))
+	return key, key != ""
+}
+
+// Idempotent handles the idempotency key of the request by the store of Idempotency. It replays
+// the response recorded for the key, or records the response of the request for the later retries
+// with the key, the server errors are not recorded. The keys are scoped to the principal returned
+// by the Scope of Idempotency, so the same key sent by different clients never shares a record.
+// It responds with 422 Unprocessable Entity if the key is reused by a different request, or 409
+// Conflict if a request with the key is in flight. It responds with 400 Bad Request if the request
+// can't be fingerprinted, for example, the body can't be read. It returns false and aborts the
+// context if the response is replayed or rejected.
+func (ctx *Context) Idempotent() bool {
+	config := Idempotency
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	key, ok := ctx.IdempotencyKey()
+	if config.Store == nil || !ok || !slices.Contains(methods, ctx.Method()) {
+		return true
+	}
+
+	key = config.storeKey(ctx, key)
+
+	fingerprint, err := ctx.idempotencyFingerprint()
+	if err != nil {
+		if !ctx.IsAborted() {
+			ctx.Abort()
+			_ = ctx.Status(http.StatusBadRequest)
+		}
+		return false
+	}
+
+	if ctx.replayIdempotentRecord(config.Store, key, fingerprint) {
+		return false
+	}
+
+	if locker, ok := config.Store.(IdempotencyLocker); ok {
+		lockTTL := config.LockTTL
+		if lockTTL <= 0 {
+			lockTTL = time.Minute
+		}
+		locked, err := locker.Lock(ctx.StdContext(), key, lockTTL)
+		if err != nil {
+			return true
+		}
+		if !locked {
+			ctx.Abort()
+			_ = ctx.Status(http.StatusConflict)
+			return false
+		}
+		ctx.onFinish(func() {
+			_ = locker.Unlock(context.WithoutCancel(ctx.StdContext()), key)
+		})
+
+		// the response may be recorded by another request between the loading and the locking.
+		if ctx.replayIdempotentRecord(config.Store, key, fingerprint) {
+			return false
+		}
+	}
+
+	ctx.CaptureResponse()
+	ctx.onFinish(func() {
+		if ctx.status == 0 || ctx.status >= http.StatusInternalServerError {
+			return
+		}
+
+		headers := config.Headers
+		if len(headers) == 0 {
+			headers = []string{
+				"Content-Type", "Content-Language", "Location", "ETag", "Last-Modified", "Cache-Control",
+			}
+		}
+		ttl := config.TTL
+		if ttl <= 0 {
+			ttl = 24 * time.Hour
+		}
+
+		record := IdempotencyRecord{Fingerprint: fingerprint, Response: ctx.CapturedResponse(headers...)}
+		_ = config.Store.Save(context.WithoutCancel(ctx.StdContext()), key, record, ttl)
+	})
+
+	return true
+}
+
+// replayIdempotentRecord loads the record of the key from the store, and replays the recorded
+// response or rejects the request with a different fingerprint. It returns false if no response is
+// recorded.
+func (ctx *Context) replayIdempotentRecord(store IdempotencyStore, key, fingerprint string) bool {
+	record, err := store.Load(ctx.StdContext(), key)
+	if err != nil {
+		return false
+	}
+
+	if record.Fingerprint != fingerprint {
+		ctx.Abort()
+		_ = ctx.Status(http.StatusUnprocessableEntity)
+		return true
+	}
+	ctx.replayIdempotentResponse(record.Response)
+	return true
+}
+
+// idempotencyFingerprint returns the fingerprint of the request by its method, path, query, and
+// body.
+func (ctx *Context) idempotencyFingerprint() (string, error) {
+	canonical, err := ctx.CanonicalRequestString(DefaultCanonicalComponents...)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayIdempotentResponse responds the recorded response, and aborts the context.
+func (ctx *Context) replayIdempotentResponse(res CapturedResponse) {
+	ctx.Abort()
+
+	for name, values := range res.Header {
+		ctx.DelHeader(name)
+		for _, value := range values {
+			ctx.AddHeader(name, value)
+		}
+	}
+	ctx.SetHeader("Idempotent-Replayed", "true")
+
+	if err := ctx.Status(res.Status); err != nil {
+		return
+	}
+	_, _ = ctx.Write(res.Body)
+	ctx.complete = true
+}
+
+// MemoryIdempotencyStore is an idempotency store that keeps the responses in memory, for
+// single-instance deployments and tests. The expired records are removed when new records are
+// saved.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyRecord
+	locks   map[string]time.Time
+}
+
+// memoryIdempotencyRecord is a record stored in memory.
+type memoryIdempotencyRecord struct {
+	record  IdempotencyRecord
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore creates an in-memory idempotency store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]memoryIdempotencyRecord),
+		locks:   make(map[string]time.Time),
+	}
+}
+
+// Load returns the record of the key.
+func (store *MemoryIdempotencyStore) Load(_ context.Context, key string) (IdempotencyRecord, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	record, ok := store.records[key]
+	if !ok || !DefaultClock.Now().Before(record.expires) {
+		return IdempotencyRecord{}, ErrIdempotencyKeyNotFound
+	}
+
+	return record.record, nil
+}
+
+// Save stores the record of the key, and removes the expired records.
+func (store *MemoryIdempotencyStore) Save(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := DefaultClock.Now()
+	for k, r := range store.records {
+		if !now.Before(r.expires) {
+			delete(store.records, k)
+		}
+	}
+
+	record.Response.Header = record.Response.Header.Clone()
+	record.Response.Body = append([]byte(nil), record.Response.Body...)
+	store.records[key] = memoryIdempotencyRecord{record: record, expires: now.Add(ttl)}
+
+	return nil
+}
+
+// Lock locks the key for the TTL.
+func (store *MemoryIdempotencyStore) Lock(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := DefaultClock.Now()
+	if expires, ok := store.locks[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	store.locks[key] = now.Add(ttl)
+
+	return true, nil
+}
+
+// Unlock unlocks the key.
+func (store *MemoryIdempotencyStore) Unlock(_ context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.locks, key)
+	return nil
+}
@@ -0,0 +1,188 @@
+package simple_context
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// idempotencyTestRequest is a request sent to the idempotent test handler.
+type idempotencyTestRequest struct {
+	method string
+	key    string
+	auth   string
+	body   string
+	// status is the status code responded by the handler.
+	status int
+}
+
+// idempotencyTestResult is the response of the idempotent test handler.
+type idempotencyTestResult struct {
+	status   int
+	body     string
+	replayed bool
+}
+
+func TestIdempotent(t *testing.T) {
+	put := func(r *idempotencyTestRequest) { r.method = http.MethodPut }
+	noKey := func(r *idempotencyTestRequest) { r.key = "" }
+
+	tests := []struct {
+		name string
+		// first and second modify the first request and its retry.
+		first  func(r *idempotencyTestRequest)
+		second func(r *idempotencyTestRequest)
+		want   []idempotencyTestResult
+	}{
+		{
+			name: "replay",
+			want: []idempotencyTestResult{
+				{status: http.StatusCreated, body: "call 1"},
+				{status: http.StatusCreated, body: "call 1", replayed: true},
+			},
+		},
+		{
+			name:   "different body",
+			second: func(r *idempotencyTestRequest) { r.body = `{"amount":2}` },
+			want: []idempotencyTestResult{
+				{status: http.StatusCreated, body: "call 1"},
+				{status: http.StatusUnprocessableEntity},
+			},
+		},
+		{
+			name:   "different principal",
+			second: func(r *idempotencyTestRequest) { r.auth = "Bearer mallory" },
+			want: []idempotencyTestResult{
+				{status: http.StatusCreated, body: "call 1"},
+				{status: http.StatusCreated, body: "call 2"},
+			},
+		},
+		{
+			name:   "different key",
+			second: func(r *idempotencyTestRequest) { r.key = "key-2" },
+			want: []idempotencyTestResult{
+				{status: http.StatusCreated, body: "call 1"},
+				{status: http.StatusCreated, body: "call 2"},
+			},
+		},
+		{
+			name:  "server error not recorded",
+			first: func(r *idempotencyTestRequest) { r.status = http.StatusServiceUnavailable },
+			want: []idempotencyTestResult{
+				{status: http.StatusServiceUnavailable, body: "call 1"},
+				{status: http.StatusCreated, body: "call 2"},
+			},
+		},
+		{
+			name:   "method not idempotent",
+			first:  put,
+			second: put,
+			want: []idempotencyTestResult{
+				{status: http.StatusCreated, body: "call 1"},
+				{status: http.StatusCreated, body: "call 2"},
+			},
+		},
+		{
+			name:   "without key",
+			first:  noKey,
+			second: noKey,
+			want: []idempotencyTestResult{
+				{status: http.StatusCreated, body: "call 1"},
+				{status: http.StatusCreated, body: "call 2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestValue(t, &Idempotency, IdempotencyConfig{Store: NewMemoryIdempotencyStore()})
+
+			calls := 0
+			for i, modify := range []func(r *idempotencyTestRequest){tt.first, tt.second} {
+				req := idempotencyTestRequest{
+					method: http.MethodPost,
+					key:    "key-1",
+					auth:   "Bearer alice",
+					body:   `{"amount":1}`,
+					status: http.StatusCreated,
+				}
+				if modify != nil {
+					modify(&req)
+				}
+
+				impl := newTestCore(req.method, "/payments")
+				impl.req.header.Set("Authorization", req.auth)
+				impl.req.body = []byte(req.body)
+				if req.key != "" {
+					impl.req.header.Set("Idempotency-Key", req.key)
+				}
+
+				res := runTestCore(t, impl, func(c core.Context) {
+					ctx := c.(*Context)
+					if !ctx.Idempotent() {
+						return
+					}
+					calls++
+					_ = ctx.String(req.status, "call "+strconv.Itoa(calls))
+				})
+
+				want := tt.want[i]
+				if res.status != want.status {
+					t.Errorf("request %d: status = %d, want %d", i, res.status, want.status)
+				}
+				if string(res.body) != want.body {
+					t.Errorf("request %d: body = %q, want %q", i, res.body, want.body)
+				}
+				replayed := res.header.Get("Idempotent-Replayed") == "true"
+				if replayed != want.replayed {
+					t.Errorf("request %d: replayed = %v, want %v", i, replayed, want.replayed)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotentInFlight(t *testing.T) {
+	setTestValue(t, &Idempotency, IdempotencyConfig{Store: NewMemoryIdempotencyStore()})
+
+	newRequest := func() *testCore {
+		impl := newTestCore(http.MethodPost, "/payments")
+		impl.req.header.Set("Idempotency-Key", "key-1")
+		impl.req.body = []byte(`{"amount":1}`)
+		return impl
+	}
+
+	var inFlight *testResponse
+	res := runTestCore(t, newRequest(), func(c core.Context) {
+		ctx := c.(*Context)
+		if !ctx.Idempotent() {
+			return
+		}
+
+		// a retry arrives while the first request is still being handled.
+		inFlight = runTestCore(t, newRequest(), func(c core.Context) {
+			if c.(*Context).Idempotent() {
+				t.Error("Idempotent() = true for the retry of an in-flight request")
+			}
+		})
+		_ = ctx.String(http.StatusCreated, "created")
+	})
+
+	if inFlight.status != http.StatusConflict {
+		t.Errorf("in-flight status = %d, want %d", inFlight.status, http.StatusConflict)
+	}
+	if res.status != http.StatusCreated {
+		t.Errorf("status = %d, want %d", res.status, http.StatusCreated)
+	}
+
+	retry := runTestCore(t, newRequest(), func(c core.Context) {
+		if c.(*Context).Idempotent() {
+			t.Error("Idempotent() = true for the retry of a completed request")
+		}
+	})
+	if string(retry.body) != "created" {
+		t.Errorf("retry body = %q, want %q", retry.body, "created")
+	}
+}
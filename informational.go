@@ -0,0 +1,68 @@
+package simple_context
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrInformationalUnsupported is returned by WriteInformational if the informational response
+// can't be sent to the client.
+var ErrInformationalUnsupported = errors.New("informational responses are not supported")
+
+// informationalResponse is implemented by the core responses that can send informational
+// responses themselves.
+type informationalResponse interface {
+	WriteInformational(code int, headers http.Header) error
+}
+
+// WriteInformational sends an informational (1xx) response with the headers before the final
+// response, like 102 Processing during a long operation or 103 Early Hints. It returns
+// ErrInformationalUnsupported if the client speaks HTTP/1.0 or the core implementation is not
+// capable of sending informational responses. The status code 101 is not allowed, protocol
+// switching must be done by the core implementation.
+func (ctx *Context) WriteInformational(code int, headers http.Header) error {
+	if code < 100 || code > 199 || code == http.StatusSwitchingProtocols {
+		return errors.New("invalid informational status code")
+	}
+	if ctx.status != 0 {
+		return errors.New("informational response after the final status code")
+	}
+	if ctx.Protocol() == "HTTP/1.0" {
+		return ErrInformationalUnsupported
+	}
+
+	if res, ok := ctx.Response().(informationalResponse); ok {
+		return res.WriteInformational(code, headers)
+	}
+
+	w := ctx.RawResponseWriter()
+	if w == nil {
+		return ErrInformationalUnsupported
+	}
+
+	// net/http sends the headers in the header map with the informational response, and keeps
+	// them for the final response, so the headers only for this response are removed after it.
+	header := w.Header()
+	saved := make(http.Header, len(headers))
+	for key, values := range headers {
+		if old, ok := header[http.CanonicalHeaderKey(key)]; ok {
+			saved[http.CanonicalHeaderKey(key)] = old
+		}
+		header[http.CanonicalHeaderKey(key)] = values
+	}
+
+	// net/http writes the informational responses to the client immediately, flushing here would
+	// send the final response header.
+	w.WriteHeader(code)
+
+	for key := range headers {
+		key = http.CanonicalHeaderKey(key)
+		if old, ok := saved[key]; ok {
+			header[key] = old
+		} else {
+			delete(header, key)
+		}
+	}
+
+	return nil
+}
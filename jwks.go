@@ -0,0 +1,252 @@
+package simple_context
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksMinRefreshInterval is the minimum interval of refetching the key set for unknown key IDs,
+	// to prevent the tokens with random key IDs from flooding the key set endpoint.
+	jwksMinRefreshInterval = time.Minute
+	// jwksRetryInterval is the interval of retrying a failed fetch of the key set.
+	jwksRetryInterval = 10 * time.Second
+	// jwksMaxBodySize is the maximum size in bytes of the key set documents.
+	jwksMaxBodySize = 1 << 20
+)
+
+// JWKSConfig is the configuration of the JWKS key provider.
+type JWKSConfig struct {
+	// Client is the HTTP client fetching the key set, the default is http.DefaultClient.
+	Client *http.Client
+	// RefreshInterval is the interval of refetching the key set, the default is 1 hour.
+	RefreshInterval time.Duration
+}
+
+// jwksProvider provides the keys in the JSON Web Key Set fetched from a URL.
+type jwksProvider struct {
+	url    string
+	config JWKSConfig
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetchedAt time.Time
+	failedAt  time.Time
+	fetchErr  error
+	// fetching is closed when the in-flight fetch finishes, the concurrent requests wait for it
+	// instead of fetching the key set again.
+	fetching chan struct{}
+}
+
+// JWKSKeys returns the key provider of the JSON Web Key Set (RFC 7517) at the URL. The key set is
+// fetched on the first use, and refetched after the refresh interval or when a token has an unknown
+// key ID. The concurrent requests share a single fetch, and a failed fetch is retried after a
+// backoff. The symmetric (oct) keys in the set are rejected, since anyone reading the public set
+// could sign tokens with them.
+func JWKSKeys(url string, config JWKSConfig) JWTKeyProvider {
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Hour
+	}
+
+	return &jwksProvider{url: url, config: config}
+}
+
+// Key returns the key of the key ID, or the only key of the set if the key ID is empty. The time is
+// read from the clock of the standard context set by the context, see ClockFromContext.
+func (p *jwksProvider) Key(ctx context.Context, kid, alg string) (any, error) {
+	now := ClockFromContext(ctx).Now()
+
+	p.mu.Lock()
+	key, ok := p.lookup(kid)
+	if !p.stale(now, ok) {
+		defer p.mu.Unlock()
+		return p.result(key, ok)
+	}
+
+	if done := p.fetching; done != nil {
+		p.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		p.mu.Lock()
+	} else {
+		done = make(chan struct{})
+		p.fetching = done
+		p.mu.Unlock()
+
+		keys, err := p.fetch(ctx)
+
+		p.mu.Lock()
+		if err != nil {
+			p.failedAt, p.fetchErr = now, err
+		} else {
+			p.keys, p.fetchedAt, p.fetchErr = keys, now, nil
+		}
+		p.fetching = nil
+		close(done)
+	}
+	defer p.mu.Unlock()
+
+	key, ok = p.lookup(kid)
+	return p.result(key, ok)
+}
+
+// stale reports whether the key set should be fetched, the fetches are skipped in the backoff of
+// a failed fetch.
+func (p *jwksProvider) stale(now time.Time, found bool) bool {
+	if p.fetchErr != nil && now.Sub(p.failedAt) < jwksRetryInterval {
+		return false
+	}
+
+	age := now.Sub(p.fetchedAt)
+	return p.keys == nil || age > p.config.RefreshInterval || (!found && age > jwksMinRefreshInterval)
+}
+
+// result returns the key found in the set, or the error of the last fetch if no key set has been
+// fetched.
+func (p *jwksProvider) result(key any, found bool) (any, error) {
+	if found {
+		return key, nil
+	}
+	if p.keys == nil && p.fetchErr != nil {
+		return nil, p.fetchErr
+	}
+
+	return nil, ErrJWTKeyNotFound
+}
+
+// lookup returns the key of the key ID in the fetched set.
+func (p *jwksProvider) lookup(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is a key of a JSON Web Key Set.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch fetches the key set and returns the keys. The keys that are not for signatures or have
+// unsupported types are skipped.
+func (p *jwksProvider) fetch(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %d", res.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, jwksMaxBodySize)).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+// errUnsupportedJWK is returned by publicKey if the key type or the curve is not supported.
+var errUnsupportedJWK = errors.New("unsupported jwk")
+
+// publicKey returns the public verification key of the JSON Web Key. The symmetric keys are not
+// supported, they must never be published in a key set.
+func (jwk jsonWebKey) publicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errUnsupportedJWK
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errUnsupportedJWK
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, errUnsupportedJWK
+		}
+		x, err := decode(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errUnsupportedJWK
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, errUnsupportedJWK
+}
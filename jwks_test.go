@@ -0,0 +1,295 @@
+package simple_context
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-amwk/core"
+)
+
+// testJWKSServer serves the key set documents, and counts the fetches.
+type testJWKSServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []map[string]string
+	status  int
+	fetches atomic.Int32
+	// release blocks the responses until it's closed if it's not nil.
+	release chan struct{}
+}
+
+func newTestJWKSServer(t *testing.T, keys ...map[string]string) *testJWKSServer {
+	t.Helper()
+
+	s := &testJWKSServer{keys: keys, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		if s.release != nil {
+			<-s.release
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.WriteHeader(s.status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// setKeys replaces the served key set and the status code.
+func (s *testJWKSServer) setKeys(status int, keys ...map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = status
+	s.keys = keys
+}
+
+// testClockContext returns a standard context carrying the clock reading the time.
+func testClockContext(now *time.Time) context.Context {
+	return context.WithValue(context.Background(), clockContextKey{}, ClockFunc(func() time.Time {
+		return *now
+	}))
+}
+
+// testEd25519JWK returns the JSON Web Key of the Ed25519 public key.
+func testEd25519JWK(kid string, pub ed25519.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"kid": kid,
+		"x":   base64.RawURLEncoding.EncodeToString(pub),
+	}
+}
+
+func TestJWKSKeys(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	octKey := map[string]string{"kty": "oct", "kid": "oct", "k": "c2VjcmV0"}
+	encKey := testEd25519JWK("enc", pub)
+	encKey["use"] = "enc"
+
+	tests := []struct {
+		name    string
+		keys    []map[string]string
+		kid     string
+		wantErr error
+	}{
+		{
+			name: "key ID",
+			keys: []map[string]string{testEd25519JWK("a", pub), testEd25519JWK("b", pub)},
+			kid:  "b",
+		},
+		{name: "only key", keys: []map[string]string{testEd25519JWK("a", pub)}, kid: ""},
+		{
+			name:    "unknown key ID",
+			keys:    []map[string]string{testEd25519JWK("a", pub)},
+			kid:     "c",
+			wantErr: ErrJWTKeyNotFound,
+		},
+		{
+			name:    "symmetric key",
+			keys:    []map[string]string{octKey},
+			kid:     "oct",
+			wantErr: ErrJWTKeyNotFound,
+		},
+		{
+			name:    "encryption key",
+			keys:    []map[string]string{encKey},
+			kid:     "enc",
+			wantErr: ErrJWTKeyNotFound,
+		},
+		{
+			name:    "unsupported curve",
+			keys:    []map[string]string{{"kty": "EC", "crv": "P-192", "kid": "ec", "x": "AA", "y": "AA"}},
+			kid:     "ec",
+			wantErr: ErrJWTKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestJWKSServer(t, tt.keys...)
+			provider := JWKSKeys(server.URL, JWKSConfig{})
+
+			key, err := provider.Key(context.Background(), tt.kid, "EdDSA")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Key() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !pub.Equal(key) {
+				t.Errorf("Key() = %v, want the public key", key)
+			}
+		})
+	}
+}
+
+func TestJWKSKeysRefresh(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	ctx := testClockContext(&now)
+	server := newTestJWKSServer(t, testEd25519JWK("a", pub))
+	provider := JWKSKeys(server.URL, JWKSConfig{RefreshInterval: time.Hour})
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		status      int
+		keys        []map[string]string
+		kid         string
+		wantErr     error
+		wantFetches int32
+	}{
+		{name: "first use", status: http.StatusOK, kid: "a", wantFetches: 1},
+		{name: "cached", advance: time.Minute / 2, status: http.StatusOK, kid: "a", wantFetches: 1},
+		{
+			name:        "unknown key ID in the min interval",
+			status:      http.StatusOK,
+			keys:        []map[string]string{testEd25519JWK("a", pub), testEd25519JWK("b", pub)},
+			kid:         "b",
+			wantErr:     ErrJWTKeyNotFound,
+			wantFetches: 1,
+		},
+		{
+			name:        "unknown key ID after the min interval",
+			advance:     time.Minute,
+			status:      http.StatusOK,
+			kid:         "b",
+			wantFetches: 2,
+		},
+		{
+			name:        "failed refresh keeps the keys",
+			advance:     2 * time.Hour,
+			status:      http.StatusInternalServerError,
+			kid:         "a",
+			wantFetches: 3,
+		},
+		{
+			name:        "backoff after the failure",
+			advance:     time.Second,
+			status:      http.StatusInternalServerError,
+			kid:         "a",
+			wantFetches: 3,
+		},
+		{
+			name:        "retry after the backoff",
+			advance:     jwksRetryInterval,
+			status:      http.StatusOK,
+			kid:         "a",
+			wantFetches: 4,
+		},
+	}
+
+	keys := []map[string]string{testEd25519JWK("a", pub)}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.keys != nil {
+			keys = step.keys
+		}
+		server.setKeys(step.status, keys...)
+
+		if _, err := provider.Key(ctx, step.kid, "EdDSA"); !errors.Is(err, step.wantErr) {
+			t.Errorf("%s: Key() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if fetches := server.fetches.Load(); fetches != step.wantFetches {
+			t.Errorf("%s: fetches = %d, want %d", step.name, fetches, step.wantFetches)
+		}
+	}
+}
+
+func TestJWKSKeysFetchError(t *testing.T) {
+	server := newTestJWKSServer(t)
+	server.setKeys(http.StatusInternalServerError)
+	provider := JWKSKeys(server.URL, JWKSConfig{})
+
+	_, err := provider.Key(context.Background(), "a", "EdDSA")
+	if err == nil || errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("Key() error = %v, want the fetch error", err)
+	}
+}
+
+func TestJWKSKeysSingleFetch(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	server := newTestJWKSServer(t, testEd25519JWK("a", pub))
+	server.release = make(chan struct{})
+	provider := JWKSKeys(server.URL, JWKSConfig{})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := provider.Key(context.Background(), "a", "EdDSA")
+			errs <- err
+		}()
+	}
+
+	for server.fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(server.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Key() error = %v", err)
+		}
+	}
+	if fetches := server.fetches.Load(); fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+}
+
+func TestJWTRejectsPublishedSymmetricKey(t *testing.T) {
+	secret := []byte("published-secret")
+	server := newTestJWKSServer(t, map[string]string{
+		"kty": "oct",
+		"kid": "oct",
+		"k":   base64.RawURLEncoding.EncodeToString(secret),
+	})
+
+	old := currentJWTVerifier.Load()
+	t.Cleanup(func() {
+		currentJWTVerifier.Store(old)
+	})
+	SetJWTVerifier(JWTConfig{Keys: JWKSKeys(server.URL, JWKSConfig{})})
+
+	encode := base64.RawURLEncoding.EncodeToString
+	input := encode([]byte(`{"alg":"HS256","kid":"oct"}`)) + "." + encode([]byte(`{"sub":"admin"}`))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	token := input + "." + encode(mac.Sum(nil))
+
+	impl := newTestCore(http.MethodGet, "/")
+	impl.req.header.Set("Authorization", "Bearer "+token)
+	runTestCore(t, impl, func(c core.Context) {
+		if _, err := c.(*Context).JWT(); !errors.Is(err, ErrJWTKeyNotFound) {
+			t.Errorf("JWT() error = %v, want %v", err, ErrJWTKeyNotFound)
+		}
+	})
+}
@@ -0,0 +1,358 @@
+package simple_context
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for crypto.Hash
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNoJWTVerifier is returned by JWT if no verifier is set by SetJWTVerifier.
+	ErrNoJWTVerifier = errors.New("no jwt verifier")
+	// ErrJWTMissing is returned by JWT if the request has no bearer token.
+	ErrJWTMissing = errors.New("jwt missing")
+	// ErrJWTMalformed is returned by JWT if the token is not a well-formed JWS compact
+	// serialization, or its algorithm is not allowed.
+	ErrJWTMalformed = errors.New("jwt malformed")
+	// ErrJWTKeyNotFound is returned by the key providers if no key matches the token.
+	ErrJWTKeyNotFound = errors.New("jwt key not found")
+	// ErrJWTSignature is returned by JWT if the signature of the token is invalid.
+	ErrJWTSignature = errors.New("jwt signature invalid")
+	// ErrJWTExpired is returned by JWT if the token is expired.
+	ErrJWTExpired = errors.New("jwt expired")
+	// ErrJWTNotValidYet is returned by JWT if the token is used before its nbf time.
+	ErrJWTNotValidYet = errors.New("jwt not valid yet")
+	// ErrJWTInvalidClaims is returned by JWT if the issuer or the audience of the token doesn't
+	// match the verifier.
+	ErrJWTInvalidClaims = errors.New("jwt claims invalid")
+)
+
+// JWTClaimsKey is the key of the verified claims in the context state, so the downstream handlers
+// and the child contexts can read them by Get.
+const JWTClaimsKey = "jwt.claims"
+
+// JWTKeyProvider provides the keys verifying the JWT signatures.
+type JWTKeyProvider interface {
+	// Key returns the key of the key ID and the algorithm, it's a []byte for HMAC, an
+	// *rsa.PublicKey for RSA, an *ecdsa.PublicKey for ECDSA, or an ed25519.PublicKey for EdDSA.
+	// The standard context carries the clock of the request, see ClockFromContext.
+	Key(ctx context.Context, kid, alg string) (any, error)
+}
+
+// staticKeyProvider provides a single key for all tokens.
+type staticKeyProvider struct {
+	key any
+}
+
+// Key returns the static key.
+func (p staticKeyProvider) Key(context.Context, string, string) (any, error) {
+	return p.key, nil
+}
+
+// StaticJWTKey returns the key provider of the single key.
+func StaticJWTKey(key any) JWTKeyProvider {
+	return staticKeyProvider{key: key}
+}
+
+// JWTConfig is the configuration of the JWT verifier.
+type JWTConfig struct {
+	// Keys is the provider of the verification keys.
+	Keys JWTKeyProvider
+	// Algorithms are the allowed algorithms, the default is all supported algorithms. The "none"
+	// algorithm is never allowed.
+	Algorithms []string
+	// Issuer is the required iss claim, empty skips the check.
+	Issuer string
+	// Audience is the audience that must be in the aud claim, empty skips the check.
+	Audience string
+	// Leeway is the tolerance of the clock skew when checking the exp and nbf claims.
+	Leeway time.Duration
+}
+
+var currentJWTVerifier atomic.Pointer[JWTConfig]
+
+// SetJWTVerifier sets the verifier of the JWTs of the contexts.
+func SetJWTVerifier(config JWTConfig) {
+	currentJWTVerifier.Store(&config)
+}
+
+// Claims is the claims of a verified JWT.
+type Claims struct {
+	// Issuer is the iss claim.
+	Issuer string
+	// Subject is the sub claim.
+	Subject string
+	// Audience is the aud claim.
+	Audience []string
+	// ExpiresAt is the exp claim, it's zero if the token has no expiry.
+	ExpiresAt time.Time
+	// NotBefore is the nbf claim.
+	NotBefore time.Time
+	// IssuedAt is the iat claim.
+	IssuedAt time.Time
+	// ID is the jti claim.
+	ID string
+	// Raw is all claims of the token, including the registered claims above.
+	Raw map[string]any
+}
+
+// Get returns the value of the claim.
+func (c *Claims) Get(name string) (any, bool) {
+	value, ok := c.Raw[name]
+	return value, ok
+}
+
+// JWT returns the claims of the bearer token of the request verified by the verifier set by
+// SetJWTVerifier. The verified claims are stored in the context state by JWTClaimsKey.
+func (ctx *Context) JWT() (*Claims, error) {
+	if value, ok := ctx.Get(JWTClaimsKey); ok {
+		if claims, ok := value.(*Claims); ok {
+			return claims, nil
+		}
+	}
+
+	config := currentJWTVerifier.Load()
+	if config == nil {
+		return nil, ErrNoJWTVerifier
+	}
+
+	token, ok := ctx.BearerToken()
+	if !ok {
+		return nil, ErrJWTMissing
+	}
+
+	claims, err := ctx.verifyJWT(config, token)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Set(JWTClaimsKey, claims)
+
+	return claims, nil
+}
+
+// RequireJWT verifies the bearer token of the request as JWT does. It responds with 401
+// Unauthorized and the Bearer WWW-Authenticate challenge, and aborts the context if the token is
+// missing or invalid.
+func (ctx *Context) RequireJWT() bool {
+	_, err := ctx.JWT()
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, ErrJWTMissing) {
+		ctx.SetHeader("WWW-Authenticate", "Bearer")
+	} else {
+		ctx.SetHeader("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	ctx.Abort()
+	_ = ctx.Status(http.StatusUnauthorized)
+
+	return false
+}
+
+// jwtHeader is the JOSE header of a JWT.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verifyJWT verifies the signature and the claims of the token.
+func (ctx *Context) verifyJWT(config *JWTConfig, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if _, ok := jwtHashes[header.Alg]; !ok {
+		return nil, ErrJWTMalformed
+	}
+	if len(config.Algorithms) > 0 && !slices.Contains(config.Algorithms, header.Alg) {
+		return nil, ErrJWTMalformed
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+
+	if config.Keys == nil {
+		return nil, ErrJWTKeyNotFound
+	}
+	key, err := config.Keys.Key(ctx.stdContextWithClock(), header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	rawPayload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	claims, err := parseClaims(rawPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := ctx.Now()
+	if !claims.ExpiresAt.IsZero() && now.After(claims.ExpiresAt.Add(config.Leeway)) {
+		return nil, ErrJWTExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Before(claims.NotBefore.Add(-config.Leeway)) {
+		return nil, ErrJWTNotValidYet
+	}
+	if config.Issuer != "" && claims.Issuer != config.Issuer {
+		return nil, ErrJWTInvalidClaims
+	}
+	if config.Audience != "" && !slices.Contains(claims.Audience, config.Audience) {
+		return nil, ErrJWTInvalidClaims
+	}
+
+	return claims, nil
+}
+
+// jwtHashes are the hash functions of the supported algorithms, EdDSA signs the message itself.
+var jwtHashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+	"EdDSA": 0,
+}
+
+// verifyJWTSignature verifies the signature of the signing input by the algorithm and the key.
+func verifyJWTSignature(alg string, key any, input string, signature []byte) error {
+	hashFunc := jwtHashes[alg]
+	var digest []byte
+	if hashFunc != 0 {
+		h := hashFunc.New()
+		h.Write([]byte(input))
+		digest = h.Sum(nil)
+	}
+
+	valid := false
+	switch alg[:2] {
+	case "HS":
+		if secret, ok := key.([]byte); ok {
+			mac := hmac.New(hashFunc.New, secret)
+			mac.Write([]byte(input))
+			valid = hmac.Equal(mac.Sum(nil), signature)
+		}
+	case "RS":
+		if pub, ok := key.(*rsa.PublicKey); ok {
+			valid = rsa.VerifyPKCS1v15(pub, hashFunc, digest, signature) == nil
+		}
+	case "PS":
+		if pub, ok := key.(*rsa.PublicKey); ok {
+			valid = rsa.VerifyPSS(pub, hashFunc, digest, signature, nil) == nil
+		}
+	case "ES":
+		if pub, ok := key.(*ecdsa.PublicKey); ok && len(signature)%2 == 0 {
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			if len(signature) == 2*size {
+				r := new(big.Int).SetBytes(signature[:size])
+				s := new(big.Int).SetBytes(signature[size:])
+				valid = ecdsa.Verify(pub, digest, r, s)
+			}
+		}
+	case "Ed":
+		if pub, ok := key.(ed25519.PublicKey); ok && len(pub) == ed25519.PublicKeySize {
+			valid = ed25519.Verify(pub, []byte(input), signature)
+		}
+	}
+
+	if !valid {
+		return ErrJWTSignature
+	}
+
+	return nil
+}
+
+// parseClaims decodes the claims of the JWT payload.
+func parseClaims(payload []byte) (*Claims, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	raw := make(map[string]any)
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, ErrJWTMalformed
+	}
+
+	claims := &Claims{Raw: raw}
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.ID, _ = raw["jti"].(string)
+
+	switch aud := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []any:
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+
+	var err error
+	if claims.ExpiresAt, err = numericDate(raw["exp"]); err != nil {
+		return nil, err
+	}
+	if claims.NotBefore, err = numericDate(raw["nbf"]); err != nil {
+		return nil, err
+	}
+	if claims.IssuedAt, err = numericDate(raw["iat"]); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// numericDate converts the NumericDate claim value to a time, it's zero if the claim is absent.
+func numericDate(value any) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, ErrJWTMalformed
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, ErrJWTMalformed
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
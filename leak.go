@@ -0,0 +1,89 @@
+package simple_context
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// LeakReport describes a context that is not released within the threshold.
+type LeakReport struct {
+	// Method is the HTTP method of the request of the context.
+	Method string
+	// Path is the path of the request of the context.
+	Path string
+	// CreatedAt is the time when the context was initialized.
+	CreatedAt time.Time
+	// Stack is the stack trace of the goroutine that initialized the context.
+	Stack []byte
+}
+
+// LeakDetectionConfig is the configuration of the leak detection of contexts.
+type LeakDetectionConfig struct {
+	// Enabled enables the leak detection. It captures a stack trace on initializing every context,
+	// so it should only be enabled for diagnostics.
+	Enabled bool
+	// Threshold is the duration after which a context that is not released is reported, the
+	// default is one minute.
+	Threshold time.Duration
+	// Report is called with the leaked contexts, it's called in its own goroutine.
+	Report func(report LeakReport)
+}
+
+// LeakDetection is the leak detection configuration of the contexts. The contexts acquired from
+// the pool by AcquireContext are tracked until they're released by ReleaseContext, the contexts
+// initialized by InitContext without the pool are never tracked.
+var LeakDetection = LeakDetectionConfig{}
+
+var leakMu sync.Mutex
+
+// trackLeak starts tracking the context if the leak detection is enabled.
+func (ctx *Context) trackLeak() {
+	ctx.untrackLeak()
+
+	config := LeakDetection
+	if !config.Enabled || config.Report == nil {
+		return
+	}
+
+	threshold := config.Threshold
+	if threshold <= 0 {
+		threshold = time.Minute
+	}
+
+	report := LeakReport{
+		CreatedAt: ctx.startTime,
+		Stack:     debug.Stack(),
+	}
+	if ctx.contextImpl != nil {
+		report.Method = ctx.Request().Method()
+		report.Path = ctx.Request().Path()
+	}
+
+	leakMu.Lock()
+	defer leakMu.Unlock()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(threshold, func() {
+		leakMu.Lock()
+		leaked := ctx.leakTimer == timer
+		ctx.leakTimer = nil
+		leakMu.Unlock()
+
+		if leaked {
+			config.Report(report)
+		}
+	})
+	ctx.leakTimer = timer
+}
+
+// untrackLeak stops tracking the context.
+func (ctx *Context) untrackLeak() {
+	leakMu.Lock()
+	defer leakMu.Unlock()
+
+	if ctx.leakTimer != nil {
+		ctx.leakTimer.Stop()
+		ctx.leakTimer = nil
+	}
+}
@@ -0,0 +1,17 @@
+package simple_context
+
+// onFinish registers a function called when the handler chain of the request finishes. The
+// functions are called in the reverse order of their registration.
+func (ctx *Context) onFinish(f func()) {
+	ctx.finishers = append(ctx.finishers, f)
+}
+
+// finish calls the registered finish functions of the request.
+func (ctx *Context) finish() {
+	for len(ctx.finishers) > 0 {
+		last := len(ctx.finishers) - 1
+		f := ctx.finishers[last]
+		ctx.finishers = ctx.finishers[:last]
+		f()
+	}
+}
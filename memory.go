@@ -0,0 +1,62 @@
+package simple_context
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrMemoryBudgetExceeded is returned when a request-scoped buffer exceeds the memory budget of
+// the request.
+var ErrMemoryBudgetExceeded = errors.New("memory budget exceeded")
+
+// DefaultMemoryBudget is the default memory budget in bytes of the request-scoped buffers of a
+// request, including the cached body and the rendered responses. Zero means unlimited.
+var DefaultMemoryBudget int64 = 0
+
+// SetMemoryBudget sets the memory budget in bytes of the request-scoped buffers of the current
+// request. Zero means unlimited.
+func (ctx *Context) SetMemoryBudget(budget int64) {
+	ctx.memoryBudget = budget
+}
+
+// MemoryUsed returns the bytes of the request-scoped buffers counted against the memory budget.
+func (ctx *Context) MemoryUsed() int64 {
+	return ctx.memoryUsed
+}
+
+// reserveMemory counts the bytes against the memory budget of the request. If the budget is
+// exceeded, it responds with 507 Insufficient Storage, aborts the context, and returns
+// ErrMemoryBudgetExceeded.
+func (ctx *Context) reserveMemory(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	if ctx.memoryBudget > 0 && ctx.memoryUsed+n > ctx.memoryBudget {
+		_ = ctx.Status(http.StatusInsufficientStorage)
+		ctx.Abort()
+		return ErrMemoryBudgetExceeded
+	}
+
+	ctx.memoryUsed += n
+	return nil
+}
+
+// checkMemory checks if the bytes fit in the remaining memory budget of the request without
+// counting them, for rejecting oversized buffers by their size hints before allocating them.
+func (ctx *Context) checkMemory(n int64) error {
+	if err := ctx.reserveMemory(n); err != nil {
+		return err
+	}
+
+	ctx.releaseMemory(n)
+	return nil
+}
+
+// releaseMemory returns the bytes to the memory budget of the request.
+func (ctx *Context) releaseMemory(n int64) {
+	ctx.memoryUsed -= n
+	if ctx.memoryUsed < 0 {
+		ctx.memoryUsed = 0
+	}
+}
@@ -0,0 +1,90 @@
+package simple_context
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MethodOverrideConfig is the configuration of the HTTP method override support.
+type MethodOverrideConfig struct {
+	// Enabled enables the method override support.
+	Enabled bool
+	// Header is the name of the header that carries the overridden method, the default is
+	// X-HTTP-Method-Override.
+	Header string
+	// FormField is the name of the urlencoded form field that carries the overridden method, the
+	// default is _method. Set it to "-" to disable the form field.
+	FormField string
+	// Methods are the methods that can be overridden to, the default is PUT, PATCH, and DELETE.
+	Methods []string
+	// AllowedOrigins are the origins allowed to override the method. The requests with an Origin
+	// header not in the list are not overridden. All origins are allowed if it's empty.
+	AllowedOrigins []string
+}
+
+// MethodOverride is the method override configuration of the contexts. The method of a request
+// can only be overridden from POST.
+var MethodOverride = MethodOverrideConfig{}
+
+// OriginalMethod returns the HTTP method of the request without the method override.
+func (ctx *Context) OriginalMethod() string {
+	return ctx.Request().Method()
+}
+
+// overriddenMethod returns the method overridden by the request header or form field, or an
+// empty string if the method is not overridden.
+func (ctx *Context) overriddenMethod() string {
+	config := MethodOverride
+	if !config.Enabled || ctx.Request().Method() != http.MethodPost {
+		return ""
+	}
+
+	if origin := ctx.Origin(); origin != "" && len(config.AllowedOrigins) > 0 {
+		allowed := false
+		for _, allowedOrigin := range config.AllowedOrigins {
+			if strings.EqualFold(origin, allowedOrigin) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return ""
+		}
+	}
+
+	header := config.Header
+	if header == "" {
+		header = "X-HTTP-Method-Override"
+	}
+	method := ctx.Header(header)
+
+	field := config.FormField
+	if field == "" {
+		field = "_method"
+	}
+	if method == "" && field != "-" && ctx.ContentType() == "application/x-www-form-urlencoded" {
+		body, err := ctx.Body()
+		if err == nil {
+			values, _ := url.ParseQuery(string(body))
+			method = values.Get(field)
+		}
+	}
+
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return ""
+	}
+
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	for _, allowedMethod := range methods {
+		if strings.EqualFold(method, allowedMethod) {
+			return method
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,259 @@
+package simple_context
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrUploadTooLarge is returned by MultipartForm if a file or the whole upload exceeds the size
+	// limits of Uploads.
+	ErrUploadTooLarge = errors.New("upload too large")
+	// ErrTooManyFiles is returned by MultipartForm if the upload has more files than the limit of
+	// Uploads.
+	ErrTooManyFiles = errors.New("too many files")
+)
+
+// UploadConfig is the configuration of the limits of the multipart uploads. Zero disables a
+// limit.
+type UploadConfig struct {
+	// MaxFileSize is the maximum bytes of a file.
+	MaxFileSize int64
+	// MaxTotalSize is the maximum bytes of the whole multipart body.
+	MaxTotalSize int64
+	// MaxFiles is the maximum count of the files.
+	MaxFiles int
+}
+
+// Uploads is the upload limit configuration of the contexts.
+var Uploads = UploadConfig{}
+
+// DefaultMultipartMemory is the maximum bytes of the multipart form kept in memory by FormFile,
+// the rest of the files are stored in temporary files.
+var DefaultMultipartMemory int64 = 32 << 20
+
+// MultipartForm parses the multipart/form-data body of the request, and returns the form. Up to
+// maxMemory bytes of the files are kept in memory, and the rest are stored in temporary files that
+// are removed when the handler chain finishes. The form is parsed once, and the later calls return
+// the same form regardless of maxMemory. The body of the net/http request is streamed to the parser
+// and checked against the limits of Uploads as it's received, and it responds with 413 Content Too
+// Large and aborts the context if they're exceeded. The body can't be read by Body after it's
+// parsed.
+func (ctx *Context) MultipartForm(maxMemory int64) (*multipart.Form, error) {
+	if ctx.multipartForm != nil {
+		return ctx.multipartForm, nil
+	}
+
+	boundary, err := ctx.multipartBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	config := Uploads
+	body, closeBody, err := ctx.uploadStream(config.MaxTotalSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody()
+
+	// the parts are checked against the limits by a scanner reading the same stream as the form
+	// parser, so the oversize uploads are rejected before they're fully received and the body is
+	// never copied.
+	pr, pw := io.Pipe()
+	scanned := make(chan error, 1)
+	go func() {
+		err := config.scan(pr, boundary)
+		if err == nil {
+			_, err = io.Copy(io.Discard, pr)
+		}
+		pr.CloseWithError(err)
+		scanned <- err
+	}()
+
+	counted := &uploadLimitReader{r: body, remaining: config.MaxTotalSize}
+	form, err := multipart.NewReader(io.TeeReader(counted, pw), boundary).ReadForm(maxMemory)
+	// the scanner gets the error of the parser, like the body limit error, instead of a truncated
+	// stream.
+	pw.CloseWithError(err)
+	if scanErr := <-scanned; scanErr != nil {
+		if form != nil {
+			_ = form.RemoveAll()
+		}
+		err = scanErr
+	}
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = ErrUploadTooLarge
+		}
+		if errors.Is(err, ErrUploadTooLarge) || errors.Is(err, ErrTooManyFiles) {
+			return nil, ctx.rejectUpload(err)
+		}
+		return nil, err
+	}
+	ctx.multipartForm = form
+	ctx.onFinish(func() {
+		_ = form.RemoveAll()
+	})
+
+	return form, nil
+}
+
+// FormFile returns the first file of the multipart form field. The form is parsed with
+// DefaultMultipartMemory if it's not parsed yet. It returns http.ErrMissingFile if the field has
+// no file.
+func (ctx *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	form, err := ctx.MultipartForm(DefaultMultipartMemory)
+	if err != nil {
+		return nil, err
+	}
+
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+
+	return files[0], nil
+}
+
+// multipartBoundary returns the boundary of the multipart/form-data body, or http.ErrNotMultipart
+// if the body is not a multipart form.
+func (ctx *Context) multipartBoundary() (string, error) {
+	mediaType, params, err := mime.ParseMediaType(ctx.Header("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return "", http.ErrNotMultipart
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return "", http.ErrMissingBoundary
+	}
+
+	return boundary, nil
+}
+
+// uploadStream returns the stream of the multipart body, which is limited to the maximum size of
+// the uploads besides the body limit of the context. The body limit of the context is not changed
+// for the later reads.
+func (ctx *Context) uploadStream(maxSize int64) (io.Reader, func(), error) {
+	if maxSize > 0 && (ctx.bodyLimit <= 0 || ctx.bodyLimit > maxSize) {
+		bodyLimit := ctx.bodyLimit
+		ctx.bodyLimit = maxSize
+		defer func() {
+			ctx.bodyLimit = bodyLimit
+		}()
+	}
+
+	return ctx.bodyStream()
+}
+
+// SaveUploadedFile saves the uploaded file to the destination path, the parent directories are
+// created if they don't exist.
+func (ctx *Context) SaveUploadedFile(fh *multipart.FileHeader, dst string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// scan reads the parts of the multipart body, and checks the sizes and the count of the files
+// against the limits.
+func (config UploadConfig) scan(r io.Reader, boundary string) error {
+	reader := multipart.NewReader(r, boundary)
+	files := 0
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if part.FileName() != "" {
+			files++
+			if config.MaxFiles > 0 && files > config.MaxFiles {
+				return ErrTooManyFiles
+			}
+		}
+
+		var content io.Reader = part
+		if part.FileName() != "" && config.MaxFileSize > 0 {
+			content = io.LimitReader(part, config.MaxFileSize+1)
+		}
+		n, err := io.Copy(io.Discard, content)
+		if err != nil {
+			return err
+		}
+		if part.FileName() != "" && config.MaxFileSize > 0 && n > config.MaxFileSize {
+			return ErrUploadTooLarge
+		}
+	}
+}
+
+// uploadLimitReader reads the multipart body up to the limit, and returns ErrUploadTooLarge if the
+// body exceeds it. Zero disables the limit.
+type uploadLimitReader struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+}
+
+// Read reads the multipart body.
+func (l *uploadLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.remaining > 0 && l.read > l.remaining {
+		return n, ErrUploadTooLarge
+	}
+
+	return n, err
+}
+
+// rejectUpload responds with 413 Content Too Large, aborts the context, and returns the error.
+func (ctx *Context) rejectUpload(err error) error {
+	ctx.Abort()
+	_ = ctx.Status(http.StatusRequestEntityTooLarge)
+
+	return err
+}
+
+// MultipartReader returns the reader of the parts of the multipart/form-data body, for processing
+// large uploads part by part without buffering them in memory or temporary files. The body of the
+// net/http request is streamed, and it can't be read by Body or MultipartForm after. The limits of
+// Uploads are not applied to the reader.
+func (ctx *Context) MultipartReader() (*multipart.Reader, error) {
+	boundary, err := ctx.multipartBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	body, closeBody, err := ctx.bodyStream()
+	if err != nil {
+		return nil, err
+	}
+	ctx.onFinish(closeBody)
+
+	return multipart.NewReader(body, boundary), nil
+}
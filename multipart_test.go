@@ -0,0 +1,277 @@
+package simple_context
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// testMultipartFile is a file part of a multipart test body.
+type testMultipartFile struct {
+	field string
+	name  string
+	size  int
+}
+
+// newTestMultipartBody returns the multipart/form-data body of the fields and files, and its
+// Content-Type header.
+func newTestMultipartBody(
+	t *testing.T, fields map[string]string, files ...testMultipartFile,
+) ([]byte, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			t.Fatalf("WriteField() error = %v", err)
+		}
+	}
+	for _, file := range files {
+		part, err := w.CreateFormFile(file.field, file.name)
+		if err != nil {
+			t.Fatalf("CreateFormFile() error = %v", err)
+		}
+		if _, err := part.Write(bytes.Repeat([]byte("x"), file.size)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+// countingReader counts the bytes read from the reader.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestMultipartForm(t *testing.T) {
+	fields := map[string]string{"title": "report"}
+
+	tests := []struct {
+		name        string
+		config      UploadConfig
+		files       []testMultipartFile
+		contentType string
+		// streamed exposes the body by the net/http request.
+		streamed   bool
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:  "buffered",
+			files: []testMultipartFile{{field: "file", name: "a.txt", size: 10}},
+		},
+		{
+			name:     "streamed",
+			files:    []testMultipartFile{{field: "file", name: "a.txt", size: 10}},
+			streamed: true,
+		},
+		{
+			name:   "files in the limits",
+			config: UploadConfig{MaxFileSize: 10, MaxFiles: 2, MaxTotalSize: 4096},
+			files: []testMultipartFile{
+				{field: "file", name: "a.txt", size: 10},
+				{field: "file", name: "b.txt", size: 10},
+			},
+			streamed: true,
+		},
+		{
+			name:       "file too large",
+			config:     UploadConfig{MaxFileSize: 10},
+			files:      []testMultipartFile{{field: "file", name: "a.txt", size: 11}},
+			wantErr:    ErrUploadTooLarge,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "streamed file too large",
+			config:     UploadConfig{MaxFileSize: 10},
+			files:      []testMultipartFile{{field: "file", name: "a.txt", size: 11}},
+			streamed:   true,
+			wantErr:    ErrUploadTooLarge,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "too many files",
+			config: UploadConfig{MaxFiles: 1},
+			files: []testMultipartFile{
+				{field: "file", name: "a.txt", size: 1},
+				{field: "file", name: "b.txt", size: 1},
+			},
+			streamed:   true,
+			wantErr:    ErrTooManyFiles,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "total too large",
+			config:     UploadConfig{MaxTotalSize: 512},
+			files:      []testMultipartFile{{field: "file", name: "a.txt", size: 1024}},
+			streamed:   true,
+			wantErr:    ErrUploadTooLarge,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "not multipart",
+			contentType: "application/json",
+			wantErr:     http.ErrNotMultipart,
+		},
+		{
+			name:        "missing boundary",
+			contentType: "multipart/form-data",
+			wantErr:     http.ErrMissingBoundary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestValue(t, &Uploads, tt.config)
+
+			body, contentType := newTestMultipartBody(t, fields, tt.files...)
+			if tt.contentType != "" {
+				contentType = tt.contentType
+			}
+			impl := newTestCore(http.MethodPost, "/upload")
+			impl.req.header.Set("Content-Type", contentType)
+			if tt.streamed {
+				impl.req.raw = &http.Request{Body: io.NopCloser(bytes.NewReader(body))}
+			} else {
+				impl.req.body = body
+			}
+
+			var bodyLimit int64
+			runTestCore(t, impl, func(c core.Context) {
+				ctx := c.(*Context)
+				ctx.LimitBody(1 << 20)
+				form, err := ctx.MultipartForm(DefaultMultipartMemory)
+				bodyLimit = ctx.bodyLimit
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("MultipartForm() error = %v, want %v", err, tt.wantErr)
+				}
+				if err != nil {
+					if ctx.IsAborted() != (tt.wantStatus != 0) {
+						t.Errorf("IsAborted() = %v, want %v", ctx.IsAborted(), tt.wantStatus != 0)
+					}
+					return
+				}
+
+				if got := form.Value["title"]; len(got) != 1 || got[0] != "report" {
+					t.Errorf("form.Value[title] = %q, want [report]", got)
+				}
+				if got := len(form.File["file"]); got != len(tt.files) {
+					t.Errorf("len(form.File[file]) = %d, want %d", got, len(tt.files))
+				}
+				if again, _ := ctx.MultipartForm(0); again != form {
+					t.Error("MultipartForm() parses the form again")
+				}
+			})
+
+			if bodyLimit != 1<<20 {
+				t.Errorf("body limit = %d after MultipartForm, want %d", bodyLimit, 1<<20)
+			}
+			if impl.res.status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", impl.res.status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMultipartFormRejectsBeforeReadingAll(t *testing.T) {
+	setTestValue(t, &Uploads, UploadConfig{MaxFileSize: 1024})
+
+	body, contentType := newTestMultipartBody(t, nil, testMultipartFile{
+		field: "file", name: "a.bin", size: 8 << 20,
+	})
+	counter := &countingReader{r: bytes.NewReader(body)}
+
+	impl := newTestCore(http.MethodPost, "/upload")
+	impl.req.header.Set("Content-Type", contentType)
+	impl.req.raw = &http.Request{Body: io.NopCloser(counter)}
+	runTestCore(t, impl, func(c core.Context) {
+		_, err := c.(*Context).MultipartForm(DefaultMultipartMemory)
+		if !errors.Is(err, ErrUploadTooLarge) {
+			t.Errorf("MultipartForm() error = %v, want %v", err, ErrUploadTooLarge)
+		}
+	})
+
+	if counter.n >= len(body)/2 {
+		t.Errorf("read %d of %d bytes before rejecting the upload", counter.n, len(body))
+	}
+}
+
+func TestFormFile(t *testing.T) {
+	setTestValue(t, &Uploads, UploadConfig{})
+
+	body, contentType := newTestMultipartBody(t, nil, testMultipartFile{
+		field: "avatar", name: "a.png", size: 16,
+	})
+
+	tests := []struct {
+		name    string
+		field   string
+		wantErr error
+	}{
+		{name: "present", field: "avatar"},
+		{name: "missing", field: "photo", wantErr: http.ErrMissingFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := newTestCore(http.MethodPost, "/upload")
+			impl.req.header.Set("Content-Type", contentType)
+			impl.req.body = body
+
+			runTestCore(t, impl, func(c core.Context) {
+				fh, err := c.(*Context).FormFile(tt.field)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("FormFile() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && (fh.Filename != "a.png" || fh.Size != 16) {
+					t.Errorf("FormFile() = %s (%d bytes), want a.png (16 bytes)", fh.Filename, fh.Size)
+				}
+			})
+		})
+	}
+}
+
+func TestMultipartReader(t *testing.T) {
+	body, contentType := newTestMultipartBody(t, map[string]string{"title": "report"})
+
+	impl := newTestCore(http.MethodPost, "/upload")
+	impl.req.header.Set("Content-Type", contentType)
+	impl.req.raw = &http.Request{Body: io.NopCloser(bytes.NewReader(body))}
+	runTestCore(t, impl, func(c core.Context) {
+		ctx := c.(*Context)
+		reader, err := ctx.MultipartReader()
+		if err != nil {
+			t.Fatalf("MultipartReader() error = %v", err)
+		}
+
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		value, _ := io.ReadAll(part)
+		if part.FormName() != "title" || string(value) != "report" {
+			t.Errorf("part = %s=%q, want title=report", part.FormName(), value)
+		}
+
+		if _, err := ctx.Body(); !errors.Is(err, ErrBodyConsumed) {
+			t.Errorf("Body() error = %v, want %v", err, ErrBodyConsumed)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package simple_context
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// ErrNotAcceptable is returned by Negotiate if none of the offers is acceptable by the client.
+var ErrNotAcceptable = errors.New("not acceptable")
+
+// acceptQuality returns the quality of the media type by the most specific matched range, or 0 if
+// no range matches.
+func acceptQuality(ranges []MediaRange, mediaType string) float64 {
+	quality := 0.0
+	best := -1
+	for _, r := range ranges {
+		if specificity := r.match(mediaType); specificity > best {
+			best = specificity
+			quality = r.Quality
+		}
+	}
+
+	return quality
+}
+
+// Accepts returns the best offer acceptable by the client by the Accept header of the request, or
+// an empty string if none of them is acceptable. The first offer is returned if the request has no
+// Accept header.
+func (ctx *Context) Accepts(offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	header := strings.Join(ctx.HeaderValues("Accept"), ",")
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := ParseAccept(header)
+	best := ""
+	bestQuality := 0.0
+	for _, offer := range offers {
+		if quality := acceptQuality(ranges, offer); quality > bestQuality {
+			best = offer
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// Offer is a representation of a response offered to the content negotiation.
+type Offer struct {
+	// MediaType is the media type of the representation.
+	MediaType string
+	// Render responds the representation. It's called with the media type of the offer, and it sets
+	// the Content-Type header and the status code itself, as the renderers like JSON do.
+	Render func(mediaType string) error
+}
+
+// Negotiate calls the render function of the best offer acceptable by the client. The offers are
+// in the preference order of the server, which decides the ties of the Accept header. It responds
+// with 406 Not Acceptable, aborts the context, and returns ErrNotAcceptable if none of the offers
+// is acceptable.
+func (ctx *Context) Negotiate(offers ...Offer) error {
+	mediaTypes := make([]string, 0, len(offers))
+	for _, offer := range offers {
+		mediaTypes = append(mediaTypes, offer.MediaType)
+	}
+
+	ctx.AddVary("Accept")
+	mediaType := ctx.Accepts(mediaTypes...)
+	for _, offer := range offers {
+		if offer.MediaType == mediaType {
+			return offer.Render(mediaType)
+		}
+	}
+
+	return ctx.notAcceptable()
+}
+
+// notAcceptable responds with 406 Not Acceptable, aborts the context, and returns
+// ErrNotAcceptable.
+func (ctx *Context) notAcceptable() error {
+	ctx.Abort()
+	if err := ctx.Status(http.StatusNotAcceptable); err != nil {
+		return err
+	}
+
+	return ErrNotAcceptable
+}
+
+// NegotiationMap is the representations of a response in the media types, used by Format.
+type NegotiationMap struct {
+	// JSON is the value encoded as JSON for the clients accepting application/json.
+	JSON any
+	// XML is the value encoded as XML for the clients accepting application/xml.
+	XML any
+	// HTML is the template executed for the clients accepting text/html.
+	HTML *template.Template
+	// Data is the data of the HTML template, the JSON value is used if it's nil.
+	Data any
+	// Text is the plain text for the clients accepting text/plain.
+	Text string
+}
+
+// Format responds the representation in the map that is the best acceptable by the client, with
+// the status code. The representations are preferred in the order of JSON, HTML, XML, and text on
+// ties. It responds with 406 Not Acceptable, aborts the context, and returns ErrNotAcceptable if
+// none of the representations is acceptable.
+func (ctx *Context) Format(code int, m NegotiationMap) error {
+	offers := make([]string, 0, 4)
+	if m.JSON != nil {
+		offers = append(offers, mimeJSON)
+	}
+	if m.HTML != nil {
+		offers = append(offers, mimeHTML)
+	}
+	if m.XML != nil {
+		offers = append(offers, mimeXML)
+	}
+	if m.Text != "" {
+		offers = append(offers, mimeText)
+	}
+
+	ctx.AddVary("Accept")
+	switch ctx.Accepts(offers...) {
+	case mimeJSON:
+		return ctx.JSON(code, m.JSON)
+	case mimeHTML:
+		data := m.Data
+		if data == nil {
+			data = m.JSON
+		}
+		return ctx.HTML(code, m.HTML, data)
+	case mimeXML:
+		return ctx.XML(code, m.XML)
+	case mimeText:
+		return ctx.String(code, m.Text)
+	}
+
+	return ctx.notAcceptable()
+}
@@ -0,0 +1,189 @@
+package simple_context
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  []string
+		offers  []string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "no accept header",
+			offers: []string{"text/html", "application/json"},
+			want:   "text/html",
+		},
+		{
+			name:   "exact match",
+			accept: []string{"application/json"},
+			offers: []string{"text/html", "application/json"},
+			want:   "application/json",
+		},
+		{
+			name:   "quality",
+			accept: []string{"text/html;q=0.5, application/json"},
+			offers: []string{"text/html", "application/json"},
+			want:   "application/json",
+		},
+		{
+			name:   "server order on ties",
+			accept: []string{"application/json, text/html"},
+			offers: []string{"text/html", "application/json"},
+			want:   "text/html",
+		},
+		{
+			name:   "wildcard",
+			accept: []string{"*/*"},
+			offers: []string{"application/xml", "application/json"},
+			want:   "application/xml",
+		},
+		{
+			name:   "most specific range",
+			accept: []string{"text/*;q=0.9, text/plain;q=0.1"},
+			offers: []string{"text/plain", "text/html"},
+			want:   "text/html",
+		},
+		{
+			name:   "repeated headers",
+			accept: []string{"text/html;q=0.1", "application/json"},
+			offers: []string{"text/html", "application/json"},
+			want:   "application/json",
+		},
+		{
+			name:    "excluded by zero quality",
+			accept:  []string{"application/json;q=0"},
+			offers:  []string{"application/json"},
+			wantErr: ErrNotAcceptable,
+		},
+		{
+			name:    "not acceptable",
+			accept:  []string{"image/png"},
+			offers:  []string{"text/html", "application/json"},
+			wantErr: ErrNotAcceptable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDefaultAbortStatus(t, 0)
+
+			impl := newTestCore(http.MethodGet, "/")
+			for _, accept := range tt.accept {
+				impl.req.header.Add("Accept", accept)
+			}
+
+			rendered := ""
+			runTestCore(t, impl, func(c core.Context) {
+				ctx := c.(*Context)
+				offers := make([]Offer, 0, len(tt.offers))
+				for _, mediaType := range tt.offers {
+					offers = append(offers, Offer{
+						MediaType: mediaType,
+						Render: func(mediaType string) error {
+							rendered = mediaType
+							ctx.SetHeader("Content-Type", mediaType)
+							return ctx.Status(http.StatusOK)
+						},
+					})
+				}
+
+				err := ctx.Negotiate(offers...)
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Negotiate() error = %v, want %v", err, tt.wantErr)
+				}
+				if aborted := ctx.IsAborted(); aborted != (tt.wantErr != nil) {
+					t.Errorf("IsAborted() = %v, want %v", aborted, tt.wantErr != nil)
+				}
+			})
+
+			if rendered != tt.want {
+				t.Errorf("rendered %q, want %q", rendered, tt.want)
+			}
+			wantStatus := http.StatusOK
+			if tt.wantErr != nil {
+				wantStatus = http.StatusNotAcceptable
+			}
+			if impl.res.status != wantStatus {
+				t.Errorf("status = %d, want %d", impl.res.status, wantStatus)
+			}
+			if vary := impl.res.header.Get("Vary"); vary != "Accept" {
+				t.Errorf("Vary = %q, want %q", vary, "Accept")
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	m := NegotiationMap{
+		JSON: map[string]string{"hello": "world"},
+		Text: "hello world",
+	}
+
+	tests := []struct {
+		name        string
+		accept      string
+		wantStatus  int
+		wantType    string
+		wantBody    string
+		wantErr     error
+		wantAborted bool
+	}{
+		{
+			name:       "json preferred",
+			accept:     "*/*",
+			wantStatus: http.StatusCreated,
+			wantType:   "application/json; charset=utf-8",
+			wantBody:   `{"hello":"world"}`,
+		},
+		{
+			name:       "text",
+			accept:     "text/plain",
+			wantStatus: http.StatusCreated,
+			wantType:   "text/plain; charset=utf-8",
+			wantBody:   "hello world",
+		},
+		{
+			name:        "not acceptable",
+			accept:      "application/xml",
+			wantStatus:  http.StatusNotAcceptable,
+			wantErr:     ErrNotAcceptable,
+			wantAborted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDefaultAbortStatus(t, 0)
+
+			impl := newTestCore(http.MethodGet, "/")
+			impl.req.header.Set("Accept", tt.accept)
+			runTestCore(t, impl, func(c core.Context) {
+				ctx := c.(*Context)
+				if err := ctx.Format(http.StatusCreated, m); !errors.Is(err, tt.wantErr) {
+					t.Errorf("Format() error = %v, want %v", err, tt.wantErr)
+				}
+				if ctx.IsAborted() != tt.wantAborted {
+					t.Errorf("IsAborted() = %v, want %v", ctx.IsAborted(), tt.wantAborted)
+				}
+			})
+
+			if impl.res.status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", impl.res.status, tt.wantStatus)
+			}
+			if got := impl.res.header.Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if string(impl.res.body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", impl.res.body, tt.wantBody)
+			}
+		})
+	}
+}
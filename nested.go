@@ -0,0 +1,387 @@
+package simple_context
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNestedKeyConflict is returned by ParseNestedValues if a key is used both as a value and as a
+// container, like "a=1&a[b]=2".
+var ErrNestedKeyConflict = errors.New("nested key conflict")
+
+// ErrNestedKeyTooDeep is returned by ParseNestedValues if a key is nested deeper than
+// MaxNestedDepth.
+var ErrNestedKeyTooDeep = errors.New("nested key too deep")
+
+// MaxNestedDepth is the maximum count of the bracketed segments of the nested keys.
+var MaxNestedDepth = 32
+
+// ParseNestedValues decodes the values with the nested bracket keys, like "a[b][0][c]=x" and
+// "a[]=y", into a tree of map[string]any, []any, and string values. The containers whose keys are
+// all indexes are converted to slices ordered by the indexes, and the keys with multiple values
+// are decoded as []any.
+func ParseNestedValues(values url.Values) (map[string]any, error) {
+	root := make(map[string]any)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path, err := splitNestedKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range values[key] {
+			if err := insertNested(root, path, value, len(values[key]) > 1); err != nil {
+				return nil, fmt.Errorf("%w: %s", err, key)
+			}
+		}
+	}
+
+	return compactNested(root).(map[string]any), nil
+}
+
+// splitNestedKey splits the key into the segments, like "a[b][]" into "a", "b", and "".
+func splitNestedKey(key string) ([]string, error) {
+	name, rest, ok := strings.Cut(key, "[")
+	if !ok || !strings.HasSuffix(rest, "]") {
+		return []string{key}, nil
+	}
+
+	path := []string{name}
+	for _, segment := range strings.Split(rest[:len(rest)-1], "][") {
+		path = append(path, segment)
+	}
+	if len(path)-1 > MaxNestedDepth {
+		return nil, ErrNestedKeyTooDeep
+	}
+
+	return path, nil
+}
+
+// nestedList is a container built by the "[]" segments, it's converted to a slice by
+// compactNested.
+type nestedList struct {
+	items []any
+}
+
+// insertNested inserts the value into the tree by the path.
+func insertNested(node map[string]any, path []string, value string, multiple bool) error {
+	for i, segment := range path {
+		last := i == len(path)-1
+
+		if segment == "" && i > 0 {
+			list, ok := node[""].(*nestedList)
+			if !ok {
+				if _, exists := node[""]; exists {
+					return ErrNestedKeyConflict
+				}
+				list = new(nestedList)
+				node[""] = list
+			}
+			if last {
+				list.items = append(list.items, value)
+				return nil
+			}
+			child := make(map[string]any)
+			list.items = append(list.items, child)
+			node = child
+			continue
+		}
+
+		if last {
+			switch existing := node[segment].(type) {
+			case nil:
+				if multiple {
+					node[segment] = &nestedList{items: []any{value}}
+				} else {
+					node[segment] = value
+				}
+			case *nestedList:
+				existing.items = append(existing.items, value)
+			default:
+				return ErrNestedKeyConflict
+			}
+			return nil
+		}
+
+		child, ok := node[segment].(map[string]any)
+		if !ok {
+			if _, exists := node[segment]; exists {
+				return ErrNestedKeyConflict
+			}
+			child = make(map[string]any)
+			node[segment] = child
+		}
+		node = child
+	}
+
+	return nil
+}
+
+// compactNested converts the lists and the maps whose keys are all indexes to slices.
+func compactNested(node any) any {
+	switch n := node.(type) {
+	case *nestedList:
+		items := make([]any, len(n.items))
+		for i, item := range n.items {
+			items[i] = compactNested(item)
+		}
+		return items
+	case map[string]any:
+		if list, ok := n[""].(*nestedList); ok && len(n) == 1 {
+			return compactNested(list)
+		}
+
+		indexes := make([]int, 0, len(n))
+		for key := range n {
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || strconv.Itoa(index) != key {
+				indexes = nil
+				break
+			}
+			indexes = append(indexes, index)
+		}
+		if len(n) > 0 && indexes != nil {
+			sort.Ints(indexes)
+			items := make([]any, len(indexes))
+			for i, index := range indexes {
+				items[i] = compactNested(n[strconv.Itoa(index)])
+			}
+			return items
+		}
+
+		for key, value := range n {
+			n[key] = compactNested(value)
+		}
+		return n
+	}
+
+	return node
+}
+
+// NestedQuery returns the query parameters of the request decoded by ParseNestedValues.
+func (ctx *Context) NestedQuery() (map[string]any, error) {
+	return ParseNestedValues(ctx.Queries())
+}
+
+// NestedPostForm returns the form body of the request decoded by ParseNestedValues.
+func (ctx *Context) NestedPostForm() (map[string]any, error) {
+	return ParseNestedValues(ctx.postFormValues())
+}
+
+// BindQuery decodes the query parameters of the request with the nested bracket keys into the
+// value, which is a pointer to a struct or a map. The struct fields are matched by their form tags
+// or their names case-insensitively, and the strings are converted to the field types. The
+// timestamps are parsed by the time policy of the context.
+func (ctx *Context) BindQuery(v any) error {
+	tree, err := ctx.NestedQuery()
+	if err != nil {
+		return err
+	}
+
+	return decodeNested(tree, v, ctx.TimePolicy())
+}
+
+// BindForm decodes the form body of the request with the nested bracket keys into the value as
+// BindQuery does.
+func (ctx *Context) BindForm(v any) error {
+	tree, err := ctx.NestedPostForm()
+	if err != nil {
+		return err
+	}
+
+	return decodeNested(tree, v, ctx.TimePolicy())
+}
+
+// DecodeNested decodes the tree returned by ParseNestedValues into the value, which is a pointer
+// to a struct or a map. The struct fields are matched by their form tags or their names
+// case-insensitively, and the fields tagged "-" are skipped. The timestamps are parsed by
+// DefaultTimePolicy.
+func DecodeNested(tree map[string]any, v any) error {
+	return decodeNested(tree, v, DefaultTimePolicy)
+}
+
+// decodeNested decodes the tree into the value, and parses the timestamps by the time policy.
+func decodeNested(tree map[string]any, v any, policy TimePolicy) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("decode nested: non-nil pointer required")
+	}
+
+	return decodeNestedValue(tree, rv.Elem(), "", policy)
+}
+
+// decodeNestedValue decodes the node into the value.
+func decodeNestedValue(node any, rv reflect.Value, path string, policy TimePolicy) error {
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeNestedValue(node, rv.Elem(), path, policy)
+	case reflect.Interface:
+		if rv.NumMethod() == 0 {
+			rv.Set(reflect.ValueOf(node))
+			return nil
+		}
+	case reflect.Struct:
+		m, ok := node.(map[string]any)
+		if !ok {
+			if s, isString := lastNestedString(node); isString && isNestedTime(rv) {
+				return decodeNestedScalar(s, rv, path, policy)
+			}
+			break
+		}
+		return decodeNestedStruct(m, rv, path, policy)
+	case reflect.Map:
+		m, ok := node.(map[string]any)
+		if !ok || rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		for key, child := range m {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeNestedValue(child, elem, path+"["+key+"]", policy); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Slice:
+		items, ok := node.([]any)
+		if !ok {
+			items = []any{node}
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeNestedValue(item, slice.Index(i), path+"["+strconv.Itoa(i)+"]", policy); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	default:
+		if s, ok := lastNestedString(node); ok {
+			return decodeNestedScalar(s, rv, path, policy)
+		}
+	}
+
+	return fmt.Errorf("decode nested %s: cannot decode %T into %s", path, node, rv.Type())
+}
+
+// decodeNestedStruct decodes the map into the exported fields of the struct.
+func decodeNestedStruct(m map[string]any, rv reflect.Value, path string, policy TimePolicy) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("form"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		child, ok := m[name]
+		if !ok {
+			for key, value := range m {
+				if strings.EqualFold(key, name) {
+					child, ok = value, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		if err := decodeNestedValue(child, rv.Field(i), path+"["+name+"]", policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lastNestedString returns the string of the node, or the last string if the node is a list.
+func lastNestedString(node any) (string, bool) {
+	if items, ok := node.([]any); ok && len(items) > 0 {
+		node = items[len(items)-1]
+	}
+
+	s, ok := node.(string)
+	return s, ok
+}
+
+// isNestedTime reports whether the value is a time.Time or a Time.
+func isNestedTime(rv reflect.Value) bool {
+	return rv.Type() == reflect.TypeFor[time.Time]() || rv.Type() == reflect.TypeFor[Time]()
+}
+
+// decodeNestedScalar converts the string to the scalar value, the timestamps are parsed by the
+// time policy.
+func decodeNestedScalar(s string, rv reflect.Value, path string, policy TimePolicy) error {
+	if isNestedTime(rv) {
+		t, err := policy.ParseTime(s)
+		if err != nil {
+			return fmt.Errorf("decode nested %s: %w", path, err)
+		}
+		if rv.Type() == reflect.TypeFor[Time]() {
+			rv.Set(reflect.ValueOf(Time{Time: t}))
+		} else {
+			rv.Set(reflect.ValueOf(t))
+		}
+		return nil
+	}
+
+	var err error
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			rv.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, rv.Type().Bits()); err == nil {
+			rv.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, rv.Type().Bits()); err == nil {
+			rv.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, rv.Type().Bits()); err == nil {
+			rv.SetFloat(f)
+		}
+	default:
+		return fmt.Errorf("decode nested %s: unsupported type %s", path, rv.Type())
+	}
+
+	if err != nil {
+		return fmt.Errorf("decode nested %s: %w", path, err)
+	}
+
+	return nil
+}
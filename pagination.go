@@ -0,0 +1,107 @@
+package simple_context
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginationDefaults is the configuration of the pagination parameters.
+type PaginationDefaults struct {
+	// PageParam is the name of the query parameter of the page number, the default is "page".
+	PageParam string
+	// PerPageParam is the name of the query parameter of the page size, the default is
+	// "per_page".
+	PerPageParam string
+	// PerPage is the page size if the request doesn't specify one, the default is 20.
+	PerPage int
+	// MaxPerPage is the maximum page size, the larger sizes are clamped to it. The default is 100.
+	MaxPerPage int
+}
+
+// paginationState is the pagination of the request parsed by Pagination.
+type paginationState struct {
+	config  PaginationDefaults
+	page    int
+	perPage int
+}
+
+// normalize fills the zero fields of the defaults.
+func (defaults PaginationDefaults) normalize() PaginationDefaults {
+	if defaults.PageParam == "" {
+		defaults.PageParam = "page"
+	}
+	if defaults.PerPageParam == "" {
+		defaults.PerPageParam = "per_page"
+	}
+	if defaults.MaxPerPage <= 0 {
+		defaults.MaxPerPage = 100
+	}
+	if defaults.PerPage <= 0 {
+		defaults.PerPage = min(20, defaults.MaxPerPage)
+	}
+
+	return defaults
+}
+
+// Pagination returns the page number, starting from 1, and the page size by the query parameters
+// of the request. The malformed or non-positive values fall back to the first page and the default
+// page size, and the page size is clamped to the maximum. The pagination is kept for
+// SetPaginationLinks.
+func (ctx *Context) Pagination(defaults PaginationDefaults) (page, perPage int) {
+	defaults = defaults.normalize()
+
+	page, err := strconv.Atoi(ctx.Query(defaults.PageParam))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err = strconv.Atoi(ctx.Query(defaults.PerPageParam))
+	if err != nil || perPage < 1 {
+		perPage = defaults.PerPage
+	}
+	perPage = min(perPage, defaults.MaxPerPage)
+
+	ctx.pagination = &paginationState{config: defaults, page: page, perPage: perPage}
+	return page, perPage
+}
+
+// SetPaginationLinks sets the Link header (RFC 8288) of the first, previous, next, and last pages,
+// and the X-Total-Count header of the total count of the items. The pages are by the pagination
+// returned by Pagination, or the default pagination if it's not called.
+func (ctx *Context) SetPaginationLinks(total int) {
+	state := ctx.pagination
+	if state == nil {
+		ctx.Pagination(PaginationDefaults{})
+		state = ctx.pagination
+	}
+
+	lastPage := max((total+state.perPage-1)/state.perPage, 1)
+
+	links := make([]string, 0, 4)
+	links = append(links, ctx.paginationLink(state, 1, "first"))
+	if state.page > 1 {
+		links = append(links, ctx.paginationLink(state, min(state.page-1, lastPage), "prev"))
+	}
+	if state.page < lastPage {
+		links = append(links, ctx.paginationLink(state, state.page+1, "next"))
+	}
+	links = append(links, ctx.paginationLink(state, lastPage, "last"))
+
+	ctx.SetHeader("Link", strings.Join(links, ", "))
+	ctx.SetHeader("X-Total-Count", strconv.Itoa(total))
+}
+
+// paginationLink returns the link of the page with the relation type, the path is prefixed by the
+// path prefix of BaseURLs.
+func (ctx *Context) paginationLink(state *paginationState, page int, rel string) string {
+	query := make(url.Values)
+	for key, values := range ctx.Queries() {
+		query[key] = values
+	}
+	query.Set(state.config.PageParam, strconv.Itoa(page))
+	query.Set(state.config.PerPageParam, strconv.Itoa(state.perPage))
+
+	target := url.URL{Path: BaseURLs.pathPrefix() + ctx.Path(), RawQuery: query.Encode()}
+	return "<" + target.String() + `>; rel="` + rel + `"`
+}
@@ -0,0 +1,135 @@
+package simple_context
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrPathParamMissing is wrapped by PathParamError if the path parameter is empty.
+	ErrPathParamMissing = errors.New("path parameter missing")
+	// ErrPathParamMalformed is wrapped by PathParamError if the path parameter can't be converted.
+	ErrPathParamMalformed = errors.New("path parameter malformed")
+)
+
+// PathParamError is the error of converting a path parameter by the typed getters.
+type PathParamError struct {
+	// Name is the name of the path parameter.
+	Name string
+	// Value is the raw value of the path parameter.
+	Value string
+	// Type is the type the value is converted to.
+	Type string
+	// Err is ErrPathParamMissing or ErrPathParamMalformed.
+	Err error
+}
+
+// Error returns the message of the error.
+func (e *PathParamError) Error() string {
+	return "path parameter " + strconv.Quote(e.Name) + ": " + e.Err.Error() + " (" + e.Type + ")"
+}
+
+// Unwrap returns the cause of the error.
+func (e *PathParamError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the status code of the response to the error, 404 Not Found if the parameter
+// is missing, or 400 Bad Request if it's malformed.
+func (e *PathParamError) StatusCode() int {
+	if errors.Is(e.Err, ErrPathParamMissing) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadRequest
+}
+
+// pathParam returns the value of the path parameter, or the error if it's empty.
+func (ctx *Context) pathParam(name, typ string) (string, error) {
+	value := ctx.PathValue(name)
+	if value == "" {
+		return "", &PathParamError{Name: name, Type: typ, Err: ErrPathParamMissing}
+	}
+
+	return value, nil
+}
+
+// PathInt returns the path parameter as an int.
+func (ctx *Context) PathInt(name string) (int, error) {
+	value, err := ctx.pathParam(name, "int")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &PathParamError{Name: name, Value: value, Type: "int", Err: ErrPathParamMalformed}
+	}
+
+	return n, nil
+}
+
+// PathInt64 returns the path parameter as an int64.
+func (ctx *Context) PathInt64(name string) (int64, error) {
+	value, err := ctx.pathParam(name, "int64")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &PathParamError{Name: name, Value: value, Type: "int64", Err: ErrPathParamMalformed}
+	}
+
+	return n, nil
+}
+
+// PathBool returns the path parameter as a bool, it accepts the values accepted by
+// strconv.ParseBool.
+func (ctx *Context) PathBool(name string) (bool, error) {
+	value, err := ctx.pathParam(name, "bool")
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &PathParamError{Name: name, Value: value, Type: "bool", Err: ErrPathParamMalformed}
+	}
+
+	return b, nil
+}
+
+// PathUUID returns the path parameter as a UUID in the canonical lowercase form, like
+// "123e4567-e89b-12d3-a456-426614174000". The value must be in the hyphenated form.
+func (ctx *Context) PathUUID(name string) (string, error) {
+	value, err := ctx.pathParam(name, "uuid")
+	if err != nil {
+		return "", err
+	}
+
+	if !isUUID(value) {
+		return "", &PathParamError{Name: name, Value: value, Type: "uuid", Err: ErrPathParamMalformed}
+	}
+
+	return strings.ToLower(value), nil
+}
+
+// isUUID checks if the value is a UUID in the hyphenated form.
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+
+	for _, i := range []int{8, 13, 18, 23} {
+		if value[i] != '-' {
+			return false
+		}
+	}
+
+	_, err := hex.DecodeString(strings.ReplaceAll(value, "-", ""))
+	return err == nil
+}
@@ -0,0 +1,38 @@
+package simple_context
+
+import (
+	"sync"
+
+	"github.com/go-amwk/core"
+)
+
+var contextPool = sync.Pool{
+	New: func() any {
+		return new(Context)
+	},
+}
+
+// AcquireContext returns an initialized context from the pool. The context should be released by
+// ReleaseContext after the response is finished, and must not be used after that.
+func AcquireContext(impl core.Context) *Context {
+	ctx := contextPool.Get().(*Context)
+	InitContext(ctx, impl)
+	ctx.trackLeak()
+	return ctx
+}
+
+// ReleaseContext clears the context and puts it back to the pool.
+func ReleaseContext(ctx *Context) {
+	ctx.untrackLeak()
+
+	ctx.state.Range(func(key, _ any) bool {
+		ctx.state.Delete(key)
+		return true
+	})
+	ctx.contextImpl = nil
+	ctx.handlers = nil
+	ctx.body = nil
+	ctx.encodedBody = nil
+
+	contextPool.Put(ctx)
+}
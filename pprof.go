@@ -0,0 +1,58 @@
+package simple_context
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// ProfileLabelsConfig is the configuration of the pprof labels set around handler execution.
+type ProfileLabelsConfig struct {
+	// Enabled enables setting the pprof labels around handler execution.
+	Enabled bool
+	// TenantKey is the key of the tenant in the context state, the tenant label is set if the
+	// value of the key is a non-empty string. The default is "tenant".
+	TenantKey string
+}
+
+// ProfileLabels is the pprof labels configuration of the contexts. When it's enabled, the handlers
+// are executed with the route, method, and tenant labels, and the labels added by
+// SetProfileLabel, so CPU profiles can be sliced by endpoint.
+var ProfileLabels = ProfileLabelsConfig{}
+
+// SetProfileLabel sets a custom pprof label for the handlers executed after it's called.
+func (ctx *Context) SetProfileLabel(key, value string) {
+	if ctx.profileLabels == nil {
+		ctx.profileLabels = make(map[string]string)
+	}
+	ctx.profileLabels[key] = value
+}
+
+// withProfileLabels calls the function with the pprof labels of the context set if the profile
+// labels are enabled.
+func (ctx *Context) withProfileLabels(f func()) {
+	config := ProfileLabels
+	if !config.Enabled {
+		f()
+		return
+	}
+
+	labels := []string{"route", ctx.Resource(), "method", ctx.Method()}
+
+	tenantKey := config.TenantKey
+	if tenantKey == "" {
+		tenantKey = "tenant"
+	}
+	if value, ok := ctx.Get(tenantKey); ok {
+		if tenant, ok := value.(string); ok && tenant != "" {
+			labels = append(labels, "tenant", tenant)
+		}
+	}
+
+	for key, value := range ctx.profileLabels {
+		labels = append(labels, key, value)
+	}
+
+	pprof.Do(context.Background(), pprof.Labels(labels...), func(context.Context) {
+		f()
+	})
+}
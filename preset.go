@@ -0,0 +1,55 @@
+package simple_context
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrUnknownHeaderPreset is returned by ApplyPreset if no preset is registered with the name.
+var ErrUnknownHeaderPreset = errors.New("unknown header preset")
+
+var headerPresets sync.Map
+
+func init() {
+	RegisterHeaderPreset("no-cache", http.Header{
+		"Cache-Control": {"no-cache, no-store, must-revalidate"},
+		"Pragma":        {"no-cache"},
+		"Expires":       {"0"},
+	})
+	RegisterHeaderPreset("download", http.Header{
+		"Content-Type":           {"application/octet-stream"},
+		"Content-Disposition":    {"attachment"},
+		"X-Content-Type-Options": {"nosniff"},
+	})
+	RegisterHeaderPreset("cors-public", http.Header{
+		"Access-Control-Allow-Origin":  {"*"},
+		"Access-Control-Allow-Methods": {"GET, HEAD, OPTIONS"},
+		"Cross-Origin-Resource-Policy": {"cross-origin"},
+	})
+}
+
+// RegisterHeaderPreset registers a bundle of response headers with the name, replacing the preset
+// registered with the name if any. The built-in presets are "no-cache", "download", and
+// "cors-public".
+func RegisterHeaderPreset(name string, headers http.Header) {
+	headerPresets.Store(name, headers.Clone())
+}
+
+// ApplyPreset sets the response headers of the preset registered with the name. The headers of
+// the preset replace the headers of the same names in the response.
+func (ctx *Context) ApplyPreset(name string) error {
+	value, ok := headerPresets.Load(name)
+	if !ok {
+		return ErrUnknownHeaderPreset
+	}
+
+	for key, values := range value.(http.Header) {
+		ctx.DelHeader(key)
+		for _, v := range values {
+			ctx.AddHeader(key, v)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package simple_context
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaRange is a media range in an Accept header.
+type MediaRange struct {
+	// Type is the lowercase type of the range, or "*".
+	Type string
+	// Subtype is the lowercase subtype of the range, or "*".
+	Subtype string
+	// Params are the media type parameters of the range, excluding the quality value and the
+	// accept extensions after it.
+	Params map[string]string
+	// Quality is the quality value of the range, between 0 and 1.
+	Quality float64
+}
+
+// QualityValue is an element of a header with quality values, like Accept-Language or
+// Accept-Encoding.
+type QualityValue struct {
+	// Value is the element without parameters, like "en-US" or "gzip".
+	Value string
+	// Quality is the quality value of the element, between 0 and 1.
+	Quality float64
+}
+
+// ParseAccept parses the Accept header into media ranges, ordered by the quality descending while
+// keeping the order of the header on ties. The ranges with invalid syntax are skipped, and the
+// ranges with zero quality are kept so they can exclude media types.
+func ParseAccept(header string) []MediaRange {
+	ranges := make([]MediaRange, 0)
+
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		if mediaType == "*" {
+			mediaType = "*/*"
+		}
+
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+			continue
+		}
+
+		r := MediaRange{Type: typ, Subtype: subtype, Quality: 1}
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(param, "=")
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			if key == "q" {
+				r.Quality = parseQuality(value)
+				break // the parameters after q are accept-extensions
+			}
+			if key != "" {
+				if r.Params == nil {
+					r.Params = make(map[string]string)
+				}
+				r.Params[key] = value
+			}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Quality > ranges[j].Quality
+	})
+
+	return ranges
+}
+
+// ParseQualityValues parses a header with quality values, like Accept-Language, Accept-Charset, or
+// Accept-Encoding, ordered by the quality descending while keeping the order of the header on
+// ties. The elements with zero quality are kept so they can exclude values.
+func ParseQualityValues(header string) []QualityValue {
+	values := make([]QualityValue, 0)
+
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		value := strings.TrimSpace(fields[0])
+		if value == "" {
+			continue
+		}
+
+		qv := QualityValue{Value: value, Quality: 1}
+		for _, param := range fields[1:] {
+			key, q, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) == "q" {
+				qv.Quality = parseQuality(q)
+			}
+		}
+
+		values = append(values, qv)
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].Quality > values[j].Quality
+	})
+
+	return values
+}
+
+// Matches checks if the media type, with optional parameters, matches the range.
+func (r MediaRange) Matches(mediaType string) bool {
+	return r.match(mediaType) >= 0
+}
+
+// match returns the specificity of the range matching the media type, or -1 if it doesn't match.
+func (r MediaRange) match(mediaType string) int {
+	mediaType, paramsPart, _ := strings.Cut(strings.ToLower(mediaType), ";")
+	typ, subtype, _ := strings.Cut(strings.TrimSpace(mediaType), "/")
+
+	specificity := 0
+	switch {
+	case r.Type == "*":
+		specificity = 1
+	case r.Type == typ && r.Subtype == "*":
+		specificity = 2
+	case r.Type == typ && r.Subtype == subtype:
+		specificity = 3
+	default:
+		return -1
+	}
+
+	if len(r.Params) > 0 {
+		params := make(map[string]string)
+		for _, param := range strings.Split(paramsPart, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			params[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+		for key, value := range r.Params {
+			if params[key] != value {
+				return -1
+			}
+		}
+		specificity += len(r.Params)
+	}
+
+	return specificity
+}
+
+// parseQuality parses the quality value, the invalid values are treated as zero.
+func parseQuality(value string) float64 {
+	q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || q < 0 || q > 1 {
+		return 0
+	}
+
+	return q
+}
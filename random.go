@@ -0,0 +1,114 @@
+package simple_context
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// DefaultRandom is the entropy source of the contexts for generating request IDs, trace IDs, and
+// other identifiers that are not secrets. It's crypto/rand.Reader by default. The session IDs, the
+// CSRF secrets, and the keys and nonces of the cookies and the Digest authentication are always
+// read from crypto/rand.Reader, so replacing it never makes them predictable.
+var DefaultRandom io.Reader = rand.Reader
+
+// seededRandom is a deterministic entropy source that hashes the seed with a counter.
+type seededRandom struct {
+	mu      sync.Mutex
+	seed    [8]byte
+	counter uint64
+	buf     []byte
+}
+
+// NewSeededRandom returns a deterministic entropy source of the seed, for snapshot tests and
+// replays that need stable generated values. It's safe for concurrent use, and it must not be used
+// in production.
+func NewSeededRandom(seed int64) io.Reader {
+	r := new(seededRandom)
+	binary.BigEndian.PutUint64(r.seed[:], uint64(seed))
+	return r
+}
+
+// Read fills the buffer with the deterministic bytes of the seed.
+func (r *seededRandom) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			var block [16]byte
+			copy(block[:8], r.seed[:])
+			binary.BigEndian.PutUint64(block[8:], r.counter)
+			r.counter++
+			sum := sha256.Sum256(block[:])
+			r.buf = sum[:]
+		}
+
+		copied := copy(p[n:], r.buf)
+		r.buf = r.buf[copied:]
+		n += copied
+	}
+
+	return n, nil
+}
+
+// SetRandom sets the entropy source of the current request.
+func (ctx *Context) SetRandom(r io.Reader) {
+	ctx.random = r
+}
+
+// Random returns the entropy source of the current request.
+func (ctx *Context) Random() io.Reader {
+	if ctx.random == nil {
+		return DefaultRandom
+	}
+
+	return ctx.random
+}
+
+// RandomBytes returns n bytes read from the entropy source of the current request. The bytes are
+// predictable if the source is replaced, so they must not be used as secrets.
+func (ctx *Context) RandomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(ctx.Random(), buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// RandomToken returns a URL-safe base64 token of n random bytes read from the entropy source of
+// the current request.
+func (ctx *Context) RandomToken(n int) (string, error) {
+	buf, err := ctx.RandomBytes(n)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// secureRandomBytes returns n bytes read from crypto/rand.Reader, for the secrets that must not
+// depend on the replaceable entropy sources.
+func secureRandomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// secureRandomToken returns a URL-safe base64 token of n bytes read from crypto/rand.Reader.
+func secureRandomToken(n int) (string, error) {
+	buf, err := secureRandomBytes(n)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
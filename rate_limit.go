@@ -0,0 +1,63 @@
+package simple_context
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SetRateLimitHeaders sets the rate limit headers of the response, both the RateLimit-Limit,
+// RateLimit-Remaining, and RateLimit-Reset headers of the IETF draft, whose reset is in seconds
+// from now, and the legacy X-RateLimit-* headers, whose reset is in Unix seconds.
+func (ctx *Context) SetRateLimitHeaders(limit, remaining int, reset time.Time) {
+	remaining = max(remaining, 0)
+	delta := max(int64(math.Ceil(reset.Sub(ctx.Now()).Seconds())), 0)
+
+	ctx.SetHeader("RateLimit-Limit", strconv.Itoa(limit))
+	ctx.SetHeader("RateLimit-Remaining", strconv.Itoa(remaining))
+	ctx.SetHeader("RateLimit-Reset", strconv.FormatInt(delta, 10))
+
+	ctx.SetHeader("X-RateLimit-Limit", strconv.Itoa(limit))
+	ctx.SetHeader("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	ctx.SetHeader("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+// RetryAfter sets the Retry-After header of the response to the delay in seconds, rounded up.
+func (ctx *Context) RetryAfter(d time.Duration) {
+	seconds := max(int64((d+time.Second-1)/time.Second), 0)
+	ctx.SetHeader("Retry-After", strconv.FormatInt(seconds, 10))
+}
+
+// RetryAfterTime sets the Retry-After header of the response to the time in the HTTP-date format.
+func (ctx *Context) RetryAfterTime(t time.Time) {
+	ctx.SetHeader("Retry-After", t.UTC().Format(http.TimeFormat))
+}
+
+// TooManyRequests responds the value with 429 Too Many Requests and aborts the context. The value
+// is responded as plain text if it's a string, or as JSON otherwise. The Retry-After header should
+// be set by RetryAfter or RetryAfterTime before.
+func (ctx *Context) TooManyRequests(v any) error {
+	return ctx.abortWithValue(http.StatusTooManyRequests, v)
+}
+
+// ServiceUnavailable responds the value with 503 Service Unavailable and aborts the context. The
+// value is responded as plain text if it's a string, or as JSON otherwise. The Retry-After header
+// should be set by RetryAfter or RetryAfterTime before.
+func (ctx *Context) ServiceUnavailable(v any) error {
+	return ctx.abortWithValue(http.StatusServiceUnavailable, v)
+}
+
+// abortWithValue aborts the context, and responds the value with the status code.
+func (ctx *Context) abortWithValue(code int, v any) error {
+	ctx.Abort()
+
+	switch v := v.(type) {
+	case nil:
+		return ctx.Status(code)
+	case string:
+		return ctx.String(code, v)
+	}
+
+	return ctx.JSON(code, v)
+}
@@ -0,0 +1,35 @@
+package simple_context
+
+import (
+	"net/http"
+)
+
+// rawRequest is implemented by the core requests backed by net/http.
+type rawRequest interface {
+	RawRequest() *http.Request
+}
+
+// rawResponse is implemented by the core responses backed by net/http.
+type rawResponse interface {
+	RawResponseWriter() http.ResponseWriter
+}
+
+// RawRequest returns the underlying *http.Request of the request, or nil if the core
+// implementation is not backed by net/http.
+func (ctx *Context) RawRequest() *http.Request {
+	if req, ok := ctx.Request().(rawRequest); ok {
+		return req.RawRequest()
+	}
+
+	return nil
+}
+
+// RawResponseWriter returns the underlying http.ResponseWriter of the response, or nil if the core
+// implementation is not backed by net/http.
+func (ctx *Context) RawResponseWriter() http.ResponseWriter {
+	if res, ok := ctx.Response().(rawResponse); ok {
+		return res.RawResponseWriter()
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package simple_context
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html/template"
+)
+
+const (
+	mimeJSON = "application/json"
+	mimeXML  = "application/xml"
+	mimeHTML = "text/html"
+	mimeText = "text/plain"
+)
+
+// JSON encodes the value as JSON, and responds it with the status code. The Time values are
+// formatted by DefaultTimePolicy, not the time policy of the context, and the time.Time values are
+// encoded as RFC 3339 strings.
+func (ctx *Context) JSON(code int, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return ctx.render(code, mimeJSON+"; charset=utf-8", body)
+}
+
+// XML encodes the value as XML, and responds it with the status code. The timestamps are encoded
+// as JSON does.
+func (ctx *Context) XML(code int, v any) error {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return ctx.render(code, mimeXML+"; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// HTML executes the template with the data and the template functions of the context, and
+// responds the result with the status code. The template must be parsed with TemplateFuncStubs if
+// it uses the template functions.
+func (ctx *Context) HTML(code int, tmpl *template.Template, data any) error {
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := clone.Funcs(ctx.TemplateFuncs()).Execute(buf, data); err != nil {
+		return err
+	}
+
+	return ctx.render(code, mimeHTML+"; charset=utf-8", buf.Bytes())
+}
+
+// String responds the plain text with the status code.
+func (ctx *Context) String(code int, s string) error {
+	return ctx.render(code, mimeText+"; charset=utf-8", []byte(s))
+}
+
+// render responds the rendered body with the status code and the content type, it fails with
+// ErrResponseComplete before touching the headers if the response has been completed.
+func (ctx *Context) render(code int, contentType string, body []byte) error {
+	if ctx.complete {
+		return ErrResponseComplete
+	}
+
+	if err := ctx.reserveMemory(int64(len(body))); err != nil {
+		return err
+	}
+	defer ctx.releaseMemory(int64(len(body)))
+
+	if ctx.applyAutoETag(code, body) {
+		ctx.complete = true
+		return nil
+	}
+
+	ctx.SetHeader("Content-Type", contentType)
+	if err := ctx.Status(code); err != nil {
+		return err
+	}
+	if err := ctx.flushHeaders(int64(len(body))); err != nil {
+		return err
+	}
+
+	_, err := ctx.Write(body)
+	ctx.complete = true
+	return err
+}
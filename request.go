@@ -0,0 +1,36 @@
+package simple_context
+
+import (
+	"strings"
+)
+
+// IsWebSocket checks if the request is a WebSocket upgrade request.
+func (ctx *Context) IsWebSocket() bool {
+	return headerContainsToken(ctx.HeaderValues("Connection"), "upgrade") &&
+		headerContainsToken(ctx.HeaderValues("Upgrade"), "websocket")
+}
+
+// IsAJAX checks if the request is sent by XMLHttpRequest, by the X-Requested-With header.
+func (ctx *Context) IsAJAX() bool {
+	return strings.EqualFold(ctx.Header("X-Requested-With"), "XMLHttpRequest")
+}
+
+// AcceptsSSE checks if the client accepts Server-Sent Events by the Accept header.
+func (ctx *Context) AcceptsSSE() bool {
+	return headerContainsToken(ctx.HeaderValues("Accept"), "text/event-stream")
+}
+
+// headerContainsToken checks if the comma-separated header values contain the token, ignoring
+// case and parameters.
+func headerContainsToken(values []string, token string) bool {
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part, _, _ = strings.Cut(part, ";")
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
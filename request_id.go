@@ -0,0 +1,116 @@
+package simple_context
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// RequestIDConfig is the configuration of the request IDs.
+type RequestIDConfig struct {
+	// Header is the name of the request and response header carrying the request ID, the default
+	// is X-Request-ID.
+	Header string
+	// Generator generates the IDs of the requests without valid incoming IDs, the default
+	// generates UUIDv7s by the clock and the entropy source of the context.
+	Generator func(ctx *Context) (string, error)
+	// MaxLength is the maximum length of the incoming IDs, the default is 128. The longer IDs
+	// and the IDs with characters other than the printable ASCII are replaced.
+	MaxLength int
+}
+
+// RequestIDs is the request ID configuration of the contexts.
+var RequestIDs = RequestIDConfig{}
+
+// requestIDKey is the key of the request ID in the standard context.
+type requestIDKey struct{}
+
+// RequestID returns the ID of the request, which is the incoming ID in the request ID header, or a
+// generated one. The ID is set to the response header, and to the standard context of the request
+// for the logging and the tracing integrations, which can read it by RequestIDFromContext. It
+// returns an empty string if the ID can't be generated.
+func (ctx *Context) RequestID() string {
+	if ctx.requestID != "" {
+		return ctx.requestID
+	}
+
+	config := RequestIDs
+	header := config.Header
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	maxLength := config.MaxLength
+	if maxLength <= 0 {
+		maxLength = 128
+	}
+
+	id := ctx.Header(header)
+	if !validRequestID(id, maxLength) {
+		generate := config.Generator
+		if generate == nil {
+			generate = (*Context).newUUIDv7
+		}
+
+		var err error
+		if id, err = generate(ctx); err != nil {
+			return ""
+		}
+	}
+
+	ctx.requestID = id
+	ctx.SetHeader(header, id)
+	ctx.SetStdContext(context.WithValue(ctx.StdContext(), requestIDKey{}, id))
+
+	return id
+}
+
+// RequestIDFromContext returns the request ID set to the standard context by RequestID.
+func RequestIDFromContext(stdCtx context.Context) (string, bool) {
+	id, ok := stdCtx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// validRequestID checks if the incoming ID is not empty, not too long, and has only printable
+// ASCII characters, so it's safe for the headers and the logs.
+func validRequestID(id string, maxLength int) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newUUIDv7 generates a UUIDv7 (RFC 9562) by the clock and the entropy source of the context.
+func (ctx *Context) newUUIDv7() (string, error) {
+	random, err := ctx.RandomBytes(10)
+	if err != nil {
+		return "", err
+	}
+
+	var uuid [16]byte
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(ctx.Now().UnixMilli()))
+	copy(uuid[:6], timestamp[2:])
+	copy(uuid[6:], random)
+	uuid[6] = 0x70 | uuid[6]&0x0f // version 7
+	uuid[8] = 0x80 | uuid[8]&0x3f // variant 10
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+
+	return string(buf), nil
+}
@@ -0,0 +1,230 @@
+package simple_context
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrRouteNotFound is returned by URLFor if no route has the name.
+var ErrRouteNotFound = errors.New("route not found")
+
+// ErrMissingRouteParam is returned by URLFor if a parameter of the route pattern is not given.
+var ErrMissingRouteParam = errors.New("missing route parameter")
+
+// routeResolver is implemented by the core contexts whose routers can resolve the patterns of the
+// named routes.
+type routeResolver interface {
+	RoutePattern(name string) (string, bool)
+}
+
+// routeMetaRequest is implemented by the core requests whose routers expose the name and the
+// metadata of the matched routes.
+type routeMetaRequest interface {
+	RouteName() string
+	RouteMeta() map[string]any
+}
+
+var (
+	routesMu   sync.RWMutex
+	routes     = make(map[string]string)
+	routeMetas = make(map[string]map[string]any)
+)
+
+// RegisterRoute registers the pattern of the named route for URLFor, for the routers that don't
+// resolve the named routes. The parameters of the pattern are in the forms of "{name}",
+// "{name...}", ":name", or "*name".
+func RegisterRoute(name, pattern string) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+
+	routes[name] = pattern
+}
+
+// routePattern returns the pattern of the named route from the router or the registered routes.
+func (ctx *Context) routePattern(name string) (string, bool) {
+	if resolver, ok := ctx.contextImpl.(routeResolver); ok {
+		if pattern, ok := resolver.RoutePattern(name); ok {
+			return pattern, true
+		}
+	}
+
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+
+	pattern, ok := routes[name]
+	return pattern, ok
+}
+
+// SetRouteMeta sets the metadata of the named route registered by RegisterRoute, like the tags
+// that the middlewares make per-route decisions by.
+func SetRouteMeta(name string, meta map[string]any) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+
+	routeMetas[name] = meta
+}
+
+// RouteName returns the name of the matched route of the request, from the router or the route
+// registered by RegisterRoute whose pattern is the resource of the request, the patterns with the
+// method of the request are preferred. It returns an empty string if the route has no name.
+func (ctx *Context) RouteName() string {
+	if req, ok := ctx.Request().(routeMetaRequest); ok {
+		return req.RouteName()
+	}
+
+	resource := ctx.Resource()
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+
+	matched := ""
+	for name, pattern := range routes {
+		method, path, hasMethod := strings.Cut(pattern, " ")
+		if !hasMethod {
+			if pattern == resource {
+				matched = name
+			}
+		} else if strings.TrimSpace(path) == resource && method == ctx.OriginalMethod() {
+			return name
+		}
+	}
+
+	return matched
+}
+
+// RouteMeta returns the metadata of the matched route of the request, from the router or the
+// metadata set by SetRouteMeta. The returned map must not be modified.
+func (ctx *Context) RouteMeta() map[string]any {
+	if req, ok := ctx.Request().(routeMetaRequest); ok {
+		return req.RouteMeta()
+	}
+
+	name := ctx.RouteName()
+	if name == "" {
+		return nil
+	}
+
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+
+	return routeMetas[name]
+}
+
+// URLFor builds the URL of the named route with the path parameters and the query parameters. The
+// parameter values are escaped, and the values of the wildcard parameters may contain slashes. The
+// method prefix of the pattern like "GET /users/{id}" is ignored.
+func (ctx *Context) URLFor(routeName string, params map[string]string, query url.Values) (string, error) {
+	pattern, ok := ctx.routePattern(routeName)
+	if !ok {
+		return "", ErrRouteNotFound
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimSpace(path)
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		name, wildcard, ok := routeParamName(segment)
+		if !ok {
+			continue
+		}
+
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrMissingRouteParam, name)
+		}
+		if wildcard {
+			parts := strings.Split(value, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+
+	path := strings.Join(segments, "/")
+	path = strings.TrimSuffix(path, "{$}")
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return path, nil
+}
+
+// routeParamName returns the name of the parameter of the pattern segment, and whether it's a
+// wildcard.
+func routeParamName(segment string) (name string, wildcard, ok bool) {
+	switch {
+	case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && segment != "{$}":
+		name = segment[1 : len(segment)-1]
+		name, wildcard = strings.CutSuffix(name, "...")
+		return name, wildcard, name != ""
+	case strings.HasPrefix(segment, ":") && len(segment) > 1:
+		return segment[1:], false, true
+	case strings.HasPrefix(segment, "*") && len(segment) > 1:
+		return segment[1:], true, true
+	}
+
+	return "", false, false
+}
+
+// BaseURLConfig is the configuration of the base URLs of the requests.
+type BaseURLConfig struct {
+	// PathPrefix is the path prefix of the application, like "/api" for the application mounted
+	// under a subpath behind a gateway.
+	PathPrefix string
+	// TrustForwardedHeaders is whether the scheme and the host are taken from the
+	// X-Forwarded-Proto and X-Forwarded-Host headers set by the proxies.
+	TrustForwardedHeaders bool
+}
+
+// BaseURLs is the base URL configuration of the contexts.
+var BaseURLs = BaseURLConfig{}
+
+// BaseURL returns the base URL of the application, combining the scheme, the host of the request,
+// and the path prefix of BaseURLs, like "https://example.com/api", for building the absolute links,
+// the Location headers, and the OAuth callbacks.
+func (ctx *Context) BaseURL() string {
+	config := BaseURLs
+
+	scheme := "http"
+	if ctx.TLS() != nil {
+		scheme = "https"
+	}
+	host := ctx.Header("Host")
+	if raw := ctx.RawRequest(); raw != nil && raw.Host != "" {
+		host = raw.Host
+	}
+
+	if config.TrustForwardedHeaders {
+		if proto := firstHeaderItem(ctx.Header("X-Forwarded-Proto")); proto != "" {
+			scheme = strings.ToLower(proto)
+		}
+		if forwardedHost := firstHeaderItem(ctx.Header("X-Forwarded-Host")); forwardedHost != "" {
+			host = forwardedHost
+		}
+	}
+
+	return scheme + "://" + host + config.pathPrefix()
+}
+
+// pathPrefix returns the normalized path prefix, which starts with a slash and has no trailing
+// slash, or an empty string if it's not set.
+func (config BaseURLConfig) pathPrefix() string {
+	prefix := strings.TrimSuffix(config.PathPrefix, "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	return prefix
+}
+
+// firstHeaderItem returns the first item of the comma-separated header value.
+func firstHeaderItem(value string) string {
+	item, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(item)
+}
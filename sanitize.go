@@ -0,0 +1,93 @@
+package simple_context
+
+import (
+	"html"
+	"html/template"
+	"strings"
+)
+
+// Policy sanitizes untrusted HTML, like the policies of bluemonday.
+type Policy interface {
+	// Sanitize returns the sanitized HTML of the input.
+	Sanitize(input string) string
+}
+
+// PolicyFunc is an adapter to allow the use of ordinary functions as sanitization policies.
+type PolicyFunc func(input string) string
+
+// Sanitize calls f(input).
+func (f PolicyFunc) Sanitize(input string) string {
+	return f(input)
+}
+
+// StrictPolicy escapes all markup of the input, so it's rendered as plain text.
+var StrictPolicy Policy = PolicyFunc(html.EscapeString)
+
+// StripTagsPolicy removes all tags from the input, and drops the contents of script and style
+// elements entirely.
+var StripTagsPolicy Policy = PolicyFunc(stripTags)
+
+// DefaultSanitizePolicy is the policy used by the sanitizeHTML template function and by
+// SanitizeHTML when a nil policy is given.
+var DefaultSanitizePolicy = StripTagsPolicy
+
+// SanitizeHTML sanitizes the untrusted HTML by the policy, and returns it as template.HTML so it
+// can be rendered by html/template without being escaped again.
+func SanitizeHTML(input string, policy Policy) template.HTML {
+	if policy == nil {
+		policy = DefaultSanitizePolicy
+	}
+
+	return template.HTML(policy.Sanitize(input))
+}
+
+// SanitizeHTML sanitizes the untrusted HTML by the policy.
+func (ctx *Context) SanitizeHTML(input string, policy Policy) template.HTML {
+	return SanitizeHTML(input, policy)
+}
+
+// stripTags removes all tags from the input and escapes the remaining text.
+func stripTags(input string) string {
+	var sb strings.Builder
+	skipUntil := ""
+
+	for len(input) > 0 {
+		start := strings.IndexByte(input, '<')
+		if start < 0 {
+			if skipUntil == "" {
+				sb.WriteString(html.EscapeString(html.UnescapeString(input)))
+			}
+			break
+		}
+
+		if skipUntil == "" {
+			sb.WriteString(html.EscapeString(html.UnescapeString(input[:start])))
+		}
+		input = input[start:]
+
+		end := strings.IndexByte(input, '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToLower(strings.TrimSpace(input[1:end]))
+		input = input[end+1:]
+
+		name := strings.TrimPrefix(tag, "/")
+		if i := strings.IndexAny(name, " \t\r\n/"); i >= 0 {
+			name = name[:i]
+		}
+
+		if skipUntil != "" {
+			if strings.HasPrefix(tag, "/") && name == skipUntil {
+				skipUntil = ""
+			}
+			continue
+		}
+		if (name == "script" || name == "style") && !strings.HasPrefix(tag, "/") &&
+			!strings.HasSuffix(tag, "/") {
+			skipUntil = name
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,335 @@
+package simple_context
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSessionNotFound is returned by the session stores if the session doesn't exist or has
+// expired.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrNoSessionStore is returned by the session operations if no session store is configured.
+var ErrNoSessionStore = errors.New("no session store")
+
+// SessionStore stores the session data by the session IDs.
+type SessionStore interface {
+	// Load returns the values of the session, or ErrSessionNotFound if it doesn't exist.
+	Load(ctx context.Context, id string) (map[string]any, error)
+	// Save stores the values of the session, which expire after the TTL.
+	Save(ctx context.Context, id string, values map[string]any, ttl time.Duration) error
+	// Delete removes the session. Deleting a session that doesn't exist is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionConfig is the configuration of the sessions.
+type SessionConfig struct {
+	// Store is the store of the session data.
+	Store SessionStore
+	// CookieName is the name of the cookie carrying the session ID, the default is "session_id".
+	CookieName string
+	// MaxAge is the lifetime of the sessions and their cookies, the default is 24 hours.
+	MaxAge time.Duration
+	// CookieOptions are the options of the session cookie, applied over CookieDefaults.
+	CookieOptions []CookieOption
+	// IdleTimeout is the duration after which a session that is not accessed expires. Zero
+	// disables the idle expiry.
+	IdleTimeout time.Duration
+	// AbsoluteLifetime is the duration after which a session expires since it's created, no matter
+	// how active it is. Zero disables the absolute expiry.
+	AbsoluteLifetime time.Duration
+}
+
+// SessionMigrator is implemented by the session stores that can move a session to a new ID
+// atomically, so the session is never lost or duplicated when RegenerateID fails halfway.
+type SessionMigrator interface {
+	// Migrate stores the values with the new ID and removes the old ID in one operation.
+	Migrate(ctx context.Context, oldID, newID string, values map[string]any, ttl time.Duration) error
+}
+
+const (
+	// sessionCreatedKey is the reserved key of the creation time of a session, in Unix seconds.
+	sessionCreatedKey = "_session_created"
+	// sessionAccessedKey is the reserved key of the last access time of a session, in Unix seconds.
+	sessionAccessedKey = "_session_accessed"
+)
+
+// Sessions is the session configuration of the contexts.
+var Sessions = SessionConfig{}
+
+// sessionIDBytes is the count of the random bytes of a session ID.
+const sessionIDBytes = 32
+
+// cookieSessionID is the ID of the sessions stored in the session cookies.
+const cookieSessionID = "cookie"
+
+// Session is the session of a request.
+type Session struct {
+	ctx       *Context
+	id        string
+	values    map[string]any
+	isNew     bool
+	destroyed bool
+}
+
+// cookieName returns the name of the session cookie.
+func (config SessionConfig) cookieName() string {
+	if config.CookieName == "" {
+		return "session_id"
+	}
+
+	return config.CookieName
+}
+
+// maxAge returns the lifetime of the sessions.
+func (config SessionConfig) maxAge() time.Duration {
+	if config.MaxAge <= 0 {
+		return 24 * time.Hour
+	}
+
+	return config.MaxAge
+}
+
+// Session returns the session of the request. The session is loaded from the store by the ID in
+// the session cookie on the first call, or a new session is started if the request has no valid
+// session.
+func (ctx *Context) Session() *Session {
+	if ctx.session != nil {
+		return ctx.session
+	}
+
+	config := Sessions
+	sess := &Session{ctx: ctx}
+	if store, ok := config.Store.(cookieSessionStore); ok {
+		if payload, err := ctx.EncryptedCookie(config.cookieName()); err == nil {
+			if values, err := store.decode(payload); err == nil {
+				sess.id = cookieSessionID
+				sess.values = values
+			}
+		}
+	} else if cookie, err := ctx.Cookie(config.cookieName()); err == nil && cookie.Value != "" && config.Store != nil {
+		if values, err := config.Store.Load(ctx.StdContext(), cookie.Value); err == nil {
+			sess.id = cookie.Value
+			sess.values = values
+		}
+	}
+	if sess.id != "" && sess.expired(config) {
+		if _, ok := config.Store.(cookieSessionStore); !ok {
+			_ = config.Store.Delete(ctx.StdContext(), sess.id)
+		}
+		sess.id = ""
+		sess.values = nil
+	}
+	if sess.id != "" {
+		sess.touch(config)
+	} else {
+		sess.isNew = true
+	}
+	if sess.values == nil {
+		sess.values = make(map[string]any)
+	}
+
+	ctx.session = sess
+	return sess
+}
+
+// ID returns the ID of the session, it's empty for a new session until it's saved. The sessions
+// of CookieSessionStore have no server-side IDs, their IDs are always "cookie".
+func (sess *Session) ID() string {
+	return sess.id
+}
+
+// IsNew checks if the session is started by the current request.
+func (sess *Session) IsNew() bool {
+	return sess.isNew
+}
+
+// Get returns the value of the key in the session.
+func (sess *Session) Get(key string) (any, bool) {
+	value, ok := sess.values[key]
+	return value, ok
+}
+
+// Set sets the value of the key in the session.
+func (sess *Session) Set(key string, value any) {
+	sess.values[key] = value
+}
+
+// Delete removes the key from the session.
+func (sess *Session) Delete(key string) {
+	delete(sess.values, key)
+}
+
+// Save stores the session and sets the session cookie. It must be called before the response
+// status and body are written.
+func (sess *Session) Save() error {
+	config := Sessions
+	if config.Store == nil {
+		return ErrNoSessionStore
+	}
+	if sess.destroyed {
+		return nil
+	}
+
+	now := sess.ctx.Now().Unix()
+	if _, ok := sess.values[sessionCreatedKey]; !ok {
+		sess.values[sessionCreatedKey] = now
+	}
+	sess.values[sessionAccessedKey] = now
+
+	if store, ok := config.Store.(cookieSessionStore); ok {
+		payload, err := store.encode(sess.values)
+		if err != nil {
+			return err
+		}
+		sess.id = cookieSessionID
+		return sess.ctx.SetEncryptedCookie(config.cookieName(), payload, sess.cookieOptions(config)...)
+	}
+
+	if sess.id == "" {
+		id, err := secureRandomToken(sessionIDBytes)
+		if err != nil {
+			return err
+		}
+		sess.id = id
+	}
+
+	if err := config.Store.Save(sess.ctx.StdContext(), sess.id, sess.values, config.maxAge()); err != nil {
+		return err
+	}
+
+	return sess.ctx.SetCookieValue(config.cookieName(), sess.id, sess.cookieOptions(config)...)
+}
+
+// Destroy removes the session from the store and deletes the session cookie.
+func (sess *Session) Destroy() error {
+	config := Sessions
+	if config.Store == nil {
+		return ErrNoSessionStore
+	}
+
+	if _, ok := config.Store.(cookieSessionStore); !ok && sess.id != "" {
+		if err := config.Store.Delete(sess.ctx.StdContext(), sess.id); err != nil {
+			return err
+		}
+	}
+	sess.id = ""
+	sess.values = make(map[string]any)
+	sess.destroyed = true
+
+	return sess.ctx.DeleteCookie(config.cookieName(), config.CookieOptions...)
+}
+
+// RegenerateID moves the session data to a new session ID and removes the old one, to prevent
+// session fixation after the privilege of the session changes, like logging in. The move is atomic
+// if the store implements SessionMigrator, otherwise the old session is kept if saving the new one
+// fails. The creation time of the session is kept, so the absolute lifetime is not extended.
+func (sess *Session) RegenerateID() error {
+	config := Sessions
+	if config.Store == nil {
+		return ErrNoSessionStore
+	}
+
+	if _, ok := config.Store.(cookieSessionStore); ok {
+		// the cookie sessions have no server-side IDs, re-encrypting them with a new nonce is
+		// enough to make the old cookie distinct from the new one.
+		sess.destroyed = false
+		return sess.Save()
+	}
+
+	oldID := sess.id
+	sess.destroyed = false
+	if oldID == "" {
+		return sess.Save()
+	}
+
+	newID, err := secureRandomToken(sessionIDBytes)
+	if err != nil {
+		return err
+	}
+
+	if migrator, ok := config.Store.(SessionMigrator); ok {
+		sess.values[sessionAccessedKey] = sess.ctx.Now().Unix()
+		err := migrator.Migrate(sess.ctx.StdContext(), oldID, newID, sess.values, config.maxAge())
+		if err != nil {
+			return err
+		}
+		sess.id = newID
+		return sess.ctx.SetCookieValue(config.cookieName(), sess.id, sess.cookieOptions(config)...)
+	}
+
+	sess.id = newID
+	if err := sess.Save(); err != nil {
+		sess.id = oldID
+		return err
+	}
+
+	return config.Store.Delete(sess.ctx.StdContext(), oldID)
+}
+
+// touch records the access time of the loaded session, so the idle timeout is measured from the
+// last access instead of the last saving. The session is saved only if the recorded access time is
+// older than a quarter of the idle timeout or a minute, to avoid writing it on every request.
+func (sess *Session) touch(config SessionConfig) {
+	if config.IdleTimeout <= 0 {
+		return
+	}
+
+	accessed, ok := unixSeconds(sess.values[sessionAccessedKey])
+	if ok && sess.ctx.Now().Sub(accessed) < min(config.IdleTimeout/4, time.Minute) {
+		return
+	}
+	_ = sess.Save()
+}
+
+// expired checks if the session exceeds the idle timeout or the absolute lifetime. The sessions of
+// CookieSessionStore also expire after MaxAge since they're saved, as the sessions in the other
+// stores do by their TTL, so a copied cookie can't be used forever.
+func (sess *Session) expired(config SessionConfig) bool {
+	now := sess.ctx.Now()
+
+	if _, ok := config.Store.(cookieSessionStore); ok {
+		saved, ok := unixSeconds(sess.values[sessionAccessedKey])
+		if !ok || now.Sub(saved) > config.maxAge() {
+			return true
+		}
+	}
+
+	if config.AbsoluteLifetime > 0 {
+		created, ok := unixSeconds(sess.values[sessionCreatedKey])
+		if ok && now.Sub(created) > config.AbsoluteLifetime {
+			return true
+		}
+	}
+
+	if config.IdleTimeout > 0 {
+		accessed, ok := unixSeconds(sess.values[sessionAccessedKey])
+		if ok && now.Sub(accessed) > config.IdleTimeout {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unixSeconds converts the stored Unix seconds, which are decoded as float64 from the cookie
+// sessions, to a time.
+func unixSeconds(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case int64:
+		return time.Unix(v, 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	case float64:
+		return time.Unix(int64(v), 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// cookieOptions returns the options of the session cookie.
+func (sess *Session) cookieOptions(config SessionConfig) []CookieOption {
+	opts := []CookieOption{WithMaxAge(config.maxAge()), WithHTTPOnly(true)}
+	return append(opts, config.CookieOptions...)
+}
@@ -0,0 +1,191 @@
+package simple_context
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// MemorySessionStore is a session store that keeps the sessions in memory, for single-instance
+// deployments and tests. The expired sessions are removed by a background garbage collector.
+//
+// It's also a reference of the SessionStore contract for external adapters like Redis or SQL:
+// Load returns ErrSessionNotFound for the missing and expired sessions, Save replaces the values
+// and extends the expiry by the TTL, and Delete is idempotent. The stores must not retain the
+// value maps passed to Save or return maps shared with other requests.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// memorySession is a session stored in memory.
+type memorySession struct {
+	values  map[string]any
+	expires time.Time
+}
+
+// NewMemorySessionStore creates an in-memory session store that removes the expired sessions every
+// GC interval. The garbage collector is disabled if the interval is not positive, the expired
+// sessions are still not loaded. The store should be closed by Close when it's no longer used.
+func NewMemorySessionStore(gcInterval time.Duration) *MemorySessionStore {
+	store := &MemorySessionStore{
+		sessions: make(map[string]memorySession),
+		stop:     make(chan struct{}),
+	}
+
+	if gcInterval > 0 {
+		go store.gc(gcInterval)
+	}
+
+	return store
+}
+
+// Load returns a copy of the values of the session.
+func (store *MemorySessionStore) Load(_ context.Context, id string) (map[string]any, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	sess, ok := store.sessions[id]
+	if !ok || !DefaultClock.Now().Before(sess.expires) {
+		return nil, ErrSessionNotFound
+	}
+
+	return copyValues(sess.values), nil
+}
+
+// Save stores a copy of the values of the session.
+func (store *MemorySessionStore) Save(_ context.Context, id string, values map[string]any, ttl time.Duration) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.sessions[id] = memorySession{
+		values:  copyValues(values),
+		expires: DefaultClock.Now().Add(ttl),
+	}
+
+	return nil
+}
+
+// Delete removes the session.
+func (store *MemorySessionStore) Delete(_ context.Context, id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.sessions, id)
+	return nil
+}
+
+// Migrate stores the values with the new ID and removes the old ID atomically.
+func (store *MemorySessionStore) Migrate(_ context.Context, oldID, newID string, values map[string]any, ttl time.Duration) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.sessions[newID] = memorySession{
+		values:  copyValues(values),
+		expires: DefaultClock.Now().Add(ttl),
+	}
+	delete(store.sessions, oldID)
+
+	return nil
+}
+
+// Len returns the count of the stored sessions, including the expired ones not collected yet.
+func (store *MemorySessionStore) Len() int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return len(store.sessions)
+}
+
+// Close stops the garbage collector of the store.
+func (store *MemorySessionStore) Close() error {
+	store.stopOnce.Do(func() {
+		close(store.stop)
+	})
+	return nil
+}
+
+// gc removes the expired sessions every interval until the store is closed.
+func (store *MemorySessionStore) gc(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-store.stop:
+			return
+		case <-ticker.C:
+			now := DefaultClock.Now()
+			store.mu.Lock()
+			for id, sess := range store.sessions {
+				if !now.Before(sess.expires) {
+					delete(store.sessions, id)
+				}
+			}
+			store.mu.Unlock()
+		}
+	}
+}
+
+// copyValues returns a shallow copy of the session values.
+func copyValues(values map[string]any) map[string]any {
+	copied := make(map[string]any, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+// CookieSessionStore is a session store that keeps the session values in the session cookie
+// itself, encrypted by the cookie encryption keys, for stateless deployments. The values are
+// encoded as JSON, so they're decoded as the JSON types (e.g. numbers as float64), and the whole
+// session must fit in a cookie (about 4 KB). The cookie encryption keys must be set by
+// SetCookieEncryptionKeys. The sessions expire after MaxAge since they're last saved, and by the
+// idle timeout and the absolute lifetime, the same as the sessions in the other stores.
+type CookieSessionStore struct{}
+
+// Load returns ErrSessionNotFound, the sessions are loaded from the cookies by the context.
+func (CookieSessionStore) Load(context.Context, string) (map[string]any, error) {
+	return nil, ErrSessionNotFound
+}
+
+// Save does nothing, the sessions are saved into the cookies by the context.
+func (CookieSessionStore) Save(context.Context, string, map[string]any, time.Duration) error {
+	return nil
+}
+
+// Delete does nothing, the session cookies are deleted by the context.
+func (CookieSessionStore) Delete(context.Context, string) error {
+	return nil
+}
+
+// encode encodes the session values into the cookie payload.
+func (CookieSessionStore) encode(values map[string]any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// decode decodes the session values from the cookie payload.
+func (CookieSessionStore) decode(payload string) (map[string]any, error) {
+	values := make(map[string]any)
+	if err := json.Unmarshal([]byte(payload), &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// cookieSessionStore is implemented by the session stores that keep the session values in the
+// session cookies.
+type cookieSessionStore interface {
+	encode(values map[string]any) (string, error)
+	decode(payload string) (map[string]any, error)
+}
@@ -0,0 +1,86 @@
+package simple_context
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+)
+
+// ResponseMismatch is a difference between a primary response and its shadow.
+type ResponseMismatch struct {
+	// Field is the differing part, "status", "body", or "header:<name>".
+	Field string
+	// Primary is the value of the primary response.
+	Primary string
+	// Shadow is the value of the shadow response.
+	Shadow string
+}
+
+// ShadowDiffConfig is the configuration of the shadow response comparison.
+type ShadowDiffConfig struct {
+	// Enabled enables the comparison.
+	Enabled bool
+	// Headers are the names of the headers to compare.
+	Headers []string
+	// Normalize normalizes the bodies before they're compared, like removing timestamps or
+	// reordering JSON keys. The bodies are compared as they are if it's nil.
+	Normalize func(body []byte) []byte
+	// Sink receives the mismatches of the requests whose responses differ.
+	Sink func(ctx *Context, mismatches []ResponseMismatch)
+}
+
+// ShadowDiff is the shadow response comparison configuration of the contexts.
+var ShadowDiff = ShadowDiffConfig{}
+
+// DiffResponses compares the primary and shadow responses by the status code, the headers of the
+// configuration, and the normalized bodies.
+func DiffResponses(primary, shadow CapturedResponse, config ShadowDiffConfig) []ResponseMismatch {
+	mismatches := make([]ResponseMismatch, 0)
+
+	if primary.Status != shadow.Status {
+		mismatches = append(mismatches, ResponseMismatch{
+			Field:   "status",
+			Primary: strconv.Itoa(primary.Status),
+			Shadow:  strconv.Itoa(shadow.Status),
+		})
+	}
+
+	for _, name := range config.Headers {
+		name = http.CanonicalHeaderKey(name)
+		if p, s := primary.Header.Get(name), shadow.Header.Get(name); p != s {
+			mismatches = append(mismatches, ResponseMismatch{Field: "header:" + name, Primary: p, Shadow: s})
+		}
+	}
+
+	primaryBody, shadowBody := primary.Body, shadow.Body
+	if config.Normalize != nil {
+		primaryBody = config.Normalize(primaryBody)
+		shadowBody = config.Normalize(shadowBody)
+	}
+	if !bytes.Equal(primaryBody, shadowBody) {
+		mismatches = append(mismatches, ResponseMismatch{
+			Field:   "body",
+			Primary: string(primaryBody),
+			Shadow:  string(shadowBody),
+		})
+	}
+
+	return mismatches
+}
+
+// CompareShadow compares the response of the request with the response of its shadow backend, and
+// reports the mismatches to the sink of ShadowDiff. The response body must be recorded by
+// CaptureResponse before it's written. It does nothing and returns nil if ShadowDiff is disabled.
+func (ctx *Context) CompareShadow(shadow CapturedResponse) []ResponseMismatch {
+	config := ShadowDiff
+	if !config.Enabled {
+		return nil
+	}
+
+	mismatches := DiffResponses(ctx.CapturedResponse(config.Headers...), shadow, config)
+	if len(mismatches) > 0 && config.Sink != nil {
+		config.Sink(ctx, mismatches)
+	}
+
+	return mismatches
+}
@@ -0,0 +1,143 @@
+package simple_context
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSignatureMissing is returned by VerifyHMACSignature if the request has no signature
+	// header.
+	ErrSignatureMissing = errors.New("signature missing")
+	// ErrSignatureInvalid is returned by VerifyHMACSignature if the signature doesn't match the
+	// body.
+	ErrSignatureInvalid = errors.New("signature invalid")
+	// ErrSignatureExpired is returned by VerifyHMACSignature if the timestamp of the signature is
+	// out of the tolerance.
+	ErrSignatureExpired = errors.New("signature expired")
+)
+
+// SignatureScheme verifies the signature header value of a request against its raw body.
+type SignatureScheme func(ctx *Context, signature string, secret, body []byte) error
+
+// VerifyHMACSignature verifies the HMAC signature in the header of the request against the raw body
+// by the scheme, like the webhooks of GitHub or Stripe. The body is cached, so it can be read or
+// bound again after the verification.
+func (ctx *Context) VerifyHMACSignature(header string, secret []byte, scheme SignatureScheme) error {
+	signature := strings.TrimSpace(ctx.Header(header))
+	if signature == "" {
+		return ErrSignatureMissing
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return err
+	}
+
+	return scheme(ctx, signature, secret, body)
+}
+
+// GitHubSignature is the scheme of the X-Hub-Signature-256 header of the GitHub webhooks, that is
+// "sha256=" followed by the hex HMAC-SHA256 of the body.
+var GitHubSignature SignatureScheme = func(ctx *Context, signature string, secret, body []byte) error {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrSignatureInvalid
+	}
+
+	return HexHMACSignature(sha256.New)(ctx, digest, secret, body)
+}
+
+// StripeSignature returns the scheme of the Stripe-Signature header of the Stripe webhooks, that is
+// the timestamp "t" and one or more "v1" hex HMAC-SHA256 of "<t>.<body>". The signatures with the
+// timestamps older or newer than the tolerance are rejected to prevent replays, zero disables the
+// check.
+func StripeSignature(tolerance time.Duration) SignatureScheme {
+	return func(ctx *Context, signature string, secret, body []byte) error {
+		var timestamp string
+		var signatures []string
+		for _, item := range strings.Split(signature, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+		if timestamp == "" || len(signatures) == 0 {
+			return ErrSignatureInvalid
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(timestamp))
+		mac.Write([]byte("."))
+		mac.Write(body)
+		expected := mac.Sum(nil)
+
+		valid := false
+		for _, s := range signatures {
+			if actual, err := hex.DecodeString(s); err == nil && hmac.Equal(actual, expected) {
+				valid = true
+			}
+		}
+		if !valid {
+			return ErrSignatureInvalid
+		}
+
+		if tolerance > 0 {
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return ErrSignatureInvalid
+			}
+			if diff := ctx.Now().Sub(time.Unix(seconds, 0)); diff > tolerance || diff < -tolerance {
+				return ErrSignatureExpired
+			}
+		}
+
+		return nil
+	}
+}
+
+// HexHMACSignature returns the scheme of the hex HMAC of the body by the hash function.
+func HexHMACSignature(h func() hash.Hash) SignatureScheme {
+	return func(_ *Context, signature string, secret, body []byte) error {
+		actual, err := hex.DecodeString(signature)
+		if err != nil {
+			return ErrSignatureInvalid
+		}
+
+		return verifyHMAC(h, secret, body, actual)
+	}
+}
+
+// Base64HMACSignature returns the scheme of the standard base64 HMAC of the body by the hash
+// function.
+func Base64HMACSignature(h func() hash.Hash) SignatureScheme {
+	return func(_ *Context, signature string, secret, body []byte) error {
+		actual, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			return ErrSignatureInvalid
+		}
+
+		return verifyHMAC(h, secret, body, actual)
+	}
+}
+
+// verifyHMAC compares the HMAC of the body with the signature in constant time.
+func verifyHMAC(h func() hash.Hash, secret, body, signature []byte) error {
+	mac := hmac.New(h, secret)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
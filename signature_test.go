@@ -0,0 +1,177 @@
+package simple_context
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-amwk/core"
+)
+
+// testHMAC returns the HMAC-SHA256 of the data by the secret.
+func testHMAC(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func TestVerifyHMACSignature(t *testing.T) {
+	secret := []byte("webhook-secret")
+	body := `{"event":"push"}`
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	stripe := hex.EncodeToString(testHMAC(secret, timestamp+"."+body))
+	sha1MAC := hmac.New(sha1.New, secret)
+	sha1MAC.Write([]byte(body))
+
+	tests := []struct {
+		name      string
+		scheme    SignatureScheme
+		signature string
+		body      string
+		advance   time.Duration
+		wantErr   error
+	}{
+		{
+			name:      "github",
+			scheme:    GitHubSignature,
+			signature: "sha256=" + hex.EncodeToString(testHMAC(secret, body)),
+			body:      body,
+		},
+		{
+			name:      "github without prefix",
+			scheme:    GitHubSignature,
+			signature: hex.EncodeToString(testHMAC(secret, body)),
+			body:      body,
+			wantErr:   ErrSignatureInvalid,
+		},
+		{
+			name:      "github tampered body",
+			scheme:    GitHubSignature,
+			signature: "sha256=" + hex.EncodeToString(testHMAC(secret, body)),
+			body:      `{"event":"delete"}`,
+			wantErr:   ErrSignatureInvalid,
+		},
+		{
+			name:      "github wrong secret",
+			scheme:    GitHubSignature,
+			signature: "sha256=" + hex.EncodeToString(testHMAC([]byte("other"), body)),
+			body:      body,
+			wantErr:   ErrSignatureInvalid,
+		},
+		{
+			name:      "missing",
+			scheme:    GitHubSignature,
+			signature: "",
+			body:      body,
+			wantErr:   ErrSignatureMissing,
+		},
+		{
+			name:      "stripe",
+			scheme:    StripeSignature(5 * time.Minute),
+			signature: "t=" + timestamp + ",v1=" + stripe,
+			body:      body,
+		},
+		{
+			name:   "stripe rotated secrets",
+			scheme: StripeSignature(5 * time.Minute),
+			signature: "t=" + timestamp + ",v1=" + hex.EncodeToString(testHMAC([]byte("old"), body)) +
+				",v1=" + stripe,
+			body: body,
+		},
+		{
+			name:      "stripe expired",
+			scheme:    StripeSignature(5 * time.Minute),
+			signature: "t=" + timestamp + ",v1=" + stripe,
+			body:      body,
+			advance:   10 * time.Minute,
+			wantErr:   ErrSignatureExpired,
+		},
+		{
+			name:      "stripe from the future",
+			scheme:    StripeSignature(5 * time.Minute),
+			signature: "t=" + timestamp + ",v1=" + stripe,
+			body:      body,
+			advance:   -10 * time.Minute,
+			wantErr:   ErrSignatureExpired,
+		},
+		{
+			name:      "stripe without tolerance",
+			scheme:    StripeSignature(0),
+			signature: "t=" + timestamp + ",v1=" + stripe,
+			body:      body,
+			advance:   24 * time.Hour,
+		},
+		{
+			name:      "stripe changed timestamp",
+			scheme:    StripeSignature(5 * time.Minute),
+			signature: "t=" + strconv.FormatInt(now.Unix()+1, 10) + ",v1=" + stripe,
+			body:      body,
+			wantErr:   ErrSignatureInvalid,
+		},
+		{
+			name:      "stripe without timestamp",
+			scheme:    StripeSignature(5 * time.Minute),
+			signature: "v1=" + stripe,
+			body:      body,
+			wantErr:   ErrSignatureInvalid,
+		},
+		{
+			name:      "hex sha1",
+			scheme:    HexHMACSignature(sha1.New),
+			signature: hex.EncodeToString(sha1MAC.Sum(nil)),
+			body:      body,
+		},
+		{
+			name:      "hex malformed",
+			scheme:    HexHMACSignature(sha256.New),
+			signature: "zz",
+			body:      body,
+			wantErr:   ErrSignatureInvalid,
+		},
+		{
+			name:      "base64",
+			scheme:    Base64HMACSignature(sha256.New),
+			signature: base64.StdEncoding.EncodeToString(testHMAC(secret, body)),
+			body:      body,
+		},
+		{
+			name:      "base64 wrong hash",
+			scheme:    Base64HMACSignature(sha1.New),
+			signature: base64.StdEncoding.EncodeToString(testHMAC(secret, body)),
+			body:      body,
+			wantErr:   ErrSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestValue(t, &DefaultClock, Clock(ClockFunc(func() time.Time {
+				return now.Add(tt.advance)
+			})))
+
+			impl := newTestCore(http.MethodPost, "/webhook")
+			impl.req.header.Set("X-Signature", tt.signature)
+			impl.req.body = []byte(tt.body)
+			runTestCore(t, impl, func(c core.Context) {
+				ctx := c.(*Context)
+				err := ctx.VerifyHMACSignature("X-Signature", secret, tt.scheme)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyHMACSignature() error = %v, want %v", err, tt.wantErr)
+				}
+
+				// the body is cached for the handlers after the verification.
+				if got, err := ctx.Body(); err != nil || string(got) != tt.body {
+					t.Errorf("Body() = %q, %v, want %q", got, err, tt.body)
+				}
+			})
+		})
+	}
+}
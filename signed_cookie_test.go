@@ -0,0 +1,143 @@
+package simple_context
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+// setSignedTestCookie sets the signed cookie by the cookie keys, and returns the Cookie header
+// sending it back.
+func setSignedTestCookie(t *testing.T, keys [][]byte, name, value string) string {
+	t.Helper()
+
+	setTestValue(t, &cookieKeys, keys)
+	res := runTestContext(t, func(c core.Context) {
+		if err := c.(*Context).SetSignedCookie(name, value); err != nil {
+			t.Fatalf("SetSignedCookie() error = %v", err)
+		}
+	})
+
+	return testCookieHeader(res)
+}
+
+func TestSignedCookie(t *testing.T) {
+	oldKey := []byte("old-key")
+	newKey := []byte("new-key")
+
+	tests := []struct {
+		name    string
+		setName string
+		signKey [][]byte
+		keys    [][]byte
+		cookie  func(header string) string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			signKey: [][]byte{newKey},
+			keys:    [][]byte{newKey},
+			want:    "alice|admin",
+		},
+		{
+			name:    "rotated key",
+			signKey: [][]byte{oldKey},
+			keys:    [][]byte{newKey, oldKey},
+			want:    "alice|admin",
+		},
+		{
+			name:    "unknown key",
+			signKey: [][]byte{oldKey},
+			keys:    [][]byte{newKey},
+			wantErr: ErrInvalidCookieSignature,
+		},
+		{
+			name:    "tampered value",
+			signKey: [][]byte{newKey},
+			keys:    [][]byte{newKey},
+			cookie: func(header string) string {
+				_, signature, _ := strings.Cut(header, ".")
+				return "user=" + "Ym9i." + signature
+			},
+			wantErr: ErrInvalidCookieSignature,
+		},
+		{
+			name:    "moved from another name",
+			setName: "other",
+			signKey: [][]byte{newKey},
+			keys:    [][]byte{newKey},
+			cookie: func(header string) string {
+				return "user" + strings.TrimPrefix(header, "other")
+			},
+			wantErr: ErrInvalidCookieSignature,
+		},
+		{
+			name:    "no signature",
+			signKey: [][]byte{newKey},
+			keys:    [][]byte{newKey},
+			cookie: func(string) string {
+				return "user=YWxpY2U"
+			},
+			wantErr: ErrInvalidCookieSignature,
+		},
+		{
+			name:    "missing",
+			signKey: [][]byte{newKey},
+			keys:    [][]byte{newKey},
+			cookie: func(string) string {
+				return ""
+			},
+			wantErr: http.ErrNoCookie,
+		},
+		{
+			name:    "no keys",
+			signKey: [][]byte{newKey},
+			keys:    nil,
+			wantErr: ErrNoCookieKeys,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setName := tt.setName
+			if setName == "" {
+				setName = "user"
+			}
+			header := setSignedTestCookie(t, tt.signKey, setName, "alice|admin")
+			if tt.cookie != nil {
+				header = tt.cookie(header)
+			}
+			setTestValue(t, &cookieKeys, tt.keys)
+
+			impl := newTestCore(http.MethodGet, "/")
+			impl.req.header.Set("Cookie", header)
+			runTestCore(t, impl, func(c core.Context) {
+				got, err := c.(*Context).SignedCookie("user")
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SignedCookie() error = %v, want %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("SignedCookie() = %q, want %q", got, tt.want)
+				}
+			})
+		})
+	}
+}
+
+func TestSetSignedCookieWithoutKeys(t *testing.T) {
+	setTestValue(t, &cookieKeys, nil)
+
+	res := runTestContext(t, func(c core.Context) {
+		if err := c.(*Context).SetSignedCookie("user", "alice"); !errors.Is(err, ErrNoCookieKeys) {
+			t.Errorf("SetSignedCookie() error = %v, want %v", err, ErrNoCookieKeys)
+		}
+	})
+
+	if cookie := res.header.Get("Set-Cookie"); cookie != "" {
+		t.Errorf("Set-Cookie = %q, want none", cookie)
+	}
+}
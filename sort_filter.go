@@ -0,0 +1,126 @@
+package simple_context
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrFilterNotAllowed is returned by FilterParams if a filter has a field or an operator that is
+// not in the allowlist.
+var ErrFilterNotAllowed = errors.New("filter not allowed")
+
+// SortField is a field of the sort order of a request.
+type SortField struct {
+	// Field is the name of the field.
+	Field string
+	// Desc is whether the field is sorted in the descending order.
+	Desc bool
+}
+
+// SortParams returns the sort order by the sort query parameter of the request, like
+// "?sort=-created_at,name" for created_at in the descending order then name in the ascending
+// order. The fields that are not allowed and the repeated fields are skipped.
+func (ctx *Context) SortParams(allowed ...string) []SortField {
+	fields := make([]SortField, 0)
+	seen := make(map[string]bool)
+
+	for _, item := range ctx.QueryArray("sort", true) {
+		field := SortField{Field: item}
+		if name, ok := strings.CutPrefix(item, "-"); ok {
+			field = SortField{Field: name, Desc: true}
+		} else if name, ok := strings.CutPrefix(item, "+"); ok {
+			field = SortField{Field: name}
+		}
+
+		if !slices.Contains(allowed, field.Field) || seen[field.Field] {
+			continue
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// FilterOp is an operator of the filters.
+type FilterOp string
+
+// The operators of the filters.
+const (
+	FilterEq  FilterOp = "eq"
+	FilterNe  FilterOp = "ne"
+	FilterGt  FilterOp = "gt"
+	FilterGte FilterOp = "gte"
+	FilterLt  FilterOp = "lt"
+	FilterLte FilterOp = "lte"
+	FilterIn  FilterOp = "in"
+)
+
+// Filter is a filter condition of a request.
+type Filter struct {
+	// Field is the name of the field.
+	Field string
+	// Op is the operator.
+	Op FilterOp
+	// Values are the operands, they're split by commas for FilterIn and have one item otherwise.
+	Values []string
+}
+
+// FilterAllowlist is the allowed operators of the fields of the filters.
+type FilterAllowlist map[string][]FilterOp
+
+// FilterParams returns the filters by the query parameters of the key in the bracket form, like
+// "filter[status]=active" for status equal to active, or "filter[age][gte]=18" with an operator.
+// The filters are ordered by the fields and the operators. It returns ErrFilterNotAllowed if a
+// filter is not in the allowlist.
+func (ctx *Context) FilterParams(key string, allowed FilterAllowlist) ([]Filter, error) {
+	tree, err := ctx.NestedQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	node, ok := tree[key].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+
+	filters := make([]Filter, 0, len(node))
+	for field, value := range node {
+		switch v := value.(type) {
+		case string:
+			filters = append(filters, Filter{Field: field, Op: FilterEq, Values: []string{v}})
+		case map[string]any:
+			for op, operand := range v {
+				s, ok := operand.(string)
+				if !ok {
+					return nil, fmt.Errorf("%w: %s", ErrFilterNotAllowed, field)
+				}
+				filter := Filter{Field: field, Op: FilterOp(op), Values: []string{s}}
+				if filter.Op == FilterIn {
+					filter.Values = strings.Split(s, ",")
+				}
+				filters = append(filters, filter)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrFilterNotAllowed, field)
+		}
+	}
+
+	for _, filter := range filters {
+		if !slices.Contains(allowed[filter.Field], filter.Op) {
+			return nil, fmt.Errorf("%w: %s %s", ErrFilterNotAllowed, filter.Field, filter.Op)
+		}
+	}
+
+	sort.Slice(filters, func(i, j int) bool {
+		if filters[i].Field != filters[j].Field {
+			return filters[i].Field < filters[j].Field
+		}
+		return filters[i].Op < filters[j].Op
+	})
+
+	return filters, nil
+}
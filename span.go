@@ -0,0 +1,163 @@
+package simple_context
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/go-amwk/core"
+)
+
+// Span is a server or handler span of a request, implemented by the adapters of the tracing SDKs
+// like OpenTelemetry.
+type Span interface {
+	// SetAttribute sets the attribute of the span, the values are strings, ints, or bools.
+	SetAttribute(key string, value any)
+	// RecordError records the error as an event of the span.
+	RecordError(err error)
+	// SetError marks the status of the span as an error with the description.
+	SetError(description string)
+	// End ends the span.
+	End()
+}
+
+// Tracer starts the spans of the requests, implemented by the adapters of the tracing SDKs like
+// OpenTelemetry. An OpenTelemetry adapter extracts the incoming trace context from the request
+// headers in StartServerSpan, and starts the spans with trace.SpanKindServer.
+type Tracer interface {
+	// StartServerSpan starts the server span of the request, and returns the standard context
+	// carrying the span.
+	StartServerSpan(stdCtx context.Context, name string, header http.Header) (context.Context, Span)
+	// StartSpan starts a child span of the span in the standard context.
+	StartSpan(stdCtx context.Context, name string) (context.Context, Span)
+	// ContextWithSpan returns a copy of the standard context carrying the span, for restoring the
+	// parent span after a handler span ends.
+	ContextWithSpan(stdCtx context.Context, span Span) context.Context
+}
+
+// TracingConfig is the configuration of the request spans.
+type TracingConfig struct {
+	// Tracer starts the spans, the spans are disabled if it's nil.
+	Tracer Tracer
+	// HandlerSpans is whether a child span is started for each handler of the chain.
+	HandlerSpans bool
+}
+
+var currentTracing atomic.Pointer[TracingConfig]
+
+// SetTracing sets the tracing configuration of the contexts. The server span of a request is
+// started when its chain starts, and ended when the chain finishes with the status code recorded.
+func SetTracing(config TracingConfig) {
+	currentTracing.Store(&config)
+}
+
+// noopSpan is the span of the requests when the tracing is disabled.
+type noopSpan struct{}
+
+// SetAttribute does nothing.
+func (noopSpan) SetAttribute(string, any) {}
+
+// RecordError does nothing.
+func (noopSpan) RecordError(error) {}
+
+// SetError does nothing.
+func (noopSpan) SetError(string) {}
+
+// End does nothing.
+func (noopSpan) End() {}
+
+// Span returns the server span of the request, or a no-op span if the tracing is disabled or the
+// chain hasn't started.
+func (ctx *Context) Span() Span {
+	if ctx.span == nil {
+		return noopSpan{}
+	}
+
+	return ctx.span
+}
+
+// startServerSpan starts the server span of the request if the tracing is enabled, and ends it
+// when the request finishes.
+func (ctx *Context) startServerSpan() {
+	config := currentTracing.Load()
+	if config == nil || config.Tracer == nil || ctx.span != nil {
+		return
+	}
+
+	name := ctx.OriginalMethod()
+	if route := ctx.Resource(); route != "" {
+		name += " " + route
+	}
+
+	stdCtx, span := config.Tracer.StartServerSpan(ctx.StdContext(), name, ctx.Headers())
+	ctx.SetStdContext(stdCtx)
+	ctx.span = span
+	ctx.activeSpan = span
+	span.SetAttribute("http.request.method", ctx.OriginalMethod())
+	span.SetAttribute("url.path", ctx.Path())
+	if route := ctx.Resource(); route != "" {
+		span.SetAttribute("http.route", route)
+	}
+	if requestID := ctx.requestID; requestID != "" {
+		span.SetAttribute("http.request.id", requestID)
+	}
+
+	ctx.onFinish(func() {
+		span.SetAttribute("http.response.status_code", ctx.status)
+		if ctx.status >= http.StatusInternalServerError {
+			span.SetError(strconv.Itoa(ctx.status) + " " + http.StatusText(ctx.status))
+		}
+		span.End()
+	})
+}
+
+// RecordError records the error on the span of the running handler, or the server span if the
+// handler spans are disabled. It does nothing if the tracing is disabled or the error is nil.
+func (ctx *Context) RecordError(err error) {
+	if err == nil || ctx.activeSpan == nil {
+		return
+	}
+
+	ctx.activeSpan.RecordError(err)
+}
+
+// withHandlerSpan calls the function in a child span of the handler if the handler spans are
+// enabled. The parent span is restored in the standard context after the handler returns, even if
+// the handler replaces the standard context, and the panics of the handler are recorded.
+func (ctx *Context) withHandlerSpan(handler core.HandlerFunc, f func()) {
+	config := currentTracing.Load()
+	if config == nil || config.Tracer == nil || !config.HandlerSpans || ctx.span == nil {
+		f()
+		return
+	}
+
+	parent, parentCtx := ctx.activeSpan, ctx.StdContext()
+	stdCtx, span := config.Tracer.StartSpan(parentCtx, handlerName(handler))
+	ctx.SetStdContext(stdCtx)
+	ctx.activeSpan = span
+	defer func() {
+		r := recover()
+		if r != nil {
+			span.RecordError(fmt.Errorf("panic: %v", r))
+			span.SetError("panic")
+		}
+		span.End()
+		ctx.activeSpan = parent
+
+		// the standard context replaced by the handler is rebuilt on the parent span, so the values
+		// and the deadlines derived by the handler are kept.
+		if current := ctx.StdContext(); current == stdCtx {
+			ctx.SetStdContext(parentCtx)
+		} else {
+			ctx.SetStdContext(config.Tracer.ContextWithSpan(current, parent))
+		}
+
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	f()
+}
@@ -0,0 +1,132 @@
+package simple_context
+
+import (
+	"runtime/metrics"
+	"sync/atomic"
+)
+
+// Stats is the runtime statistics of a context.
+type Stats struct {
+	// StateKeys is the count of keys in the context state.
+	StateKeys int
+	// HandlersExecuted is the count of handlers executed by the context.
+	HandlersExecuted int
+	// BytesRead is the count of request body bytes read through the context.
+	BytesRead int64
+	// BytesWritten is the count of response body bytes written through the context.
+	BytesWritten int64
+	// Allocations is the count of heap allocations of the process since the context was
+	// initialized. It's only available when StatsAllocations is enabled, and includes the
+	// allocations of other goroutines.
+	Allocations uint64
+}
+
+// AggregateStats is the runtime statistics of all contexts in the process.
+type AggregateStats struct {
+	// Contexts is the count of initialized contexts.
+	Contexts uint64
+	// HandlersExecuted is the count of handlers executed by all contexts.
+	HandlersExecuted uint64
+	// BytesRead is the count of request body bytes read through all contexts.
+	BytesRead uint64
+	// BytesWritten is the count of response body bytes written through all contexts.
+	BytesWritten uint64
+}
+
+// StatsAllocations enables tracking of the heap allocations in context statistics. It reads the
+// allocation counter of runtime/metrics on initializing contexts, which doesn't stop the world.
+// The counter doesn't include the tiny objects combined by the allocator, so the counts are
+// approximate.
+var StatsAllocations = false
+
+var aggregateStats struct {
+	contexts         atomic.Uint64
+	handlersExecuted atomic.Uint64
+	bytesRead        atomic.Uint64
+	bytesWritten     atomic.Uint64
+}
+
+// contextStats is the statistics counters of a context.
+type contextStats struct {
+	handlersExecuted int
+	bytesRead        int64
+	bytesWritten     int64
+	mallocs          uint64
+}
+
+// Stats returns the runtime statistics of the context.
+func (ctx *Context) Stats() Stats {
+	stats := Stats{
+		HandlersExecuted: ctx.stats.handlersExecuted,
+		BytesRead:        ctx.stats.bytesRead,
+		BytesWritten:     ctx.stats.bytesWritten,
+	}
+
+	ctx.state.Range(func(_, _ any) bool {
+		stats.StateKeys++
+		return true
+	})
+
+	if StatsAllocations && ctx.stats.mallocs > 0 {
+		stats.Allocations = readMallocs() - ctx.stats.mallocs
+	}
+
+	return stats
+}
+
+// GetAggregateStats returns the runtime statistics of all contexts in the process.
+func GetAggregateStats() AggregateStats {
+	return AggregateStats{
+		Contexts:         aggregateStats.contexts.Load(),
+		HandlersExecuted: aggregateStats.handlersExecuted.Load(),
+		BytesRead:        aggregateStats.bytesRead.Load(),
+		BytesWritten:     aggregateStats.bytesWritten.Load(),
+	}
+}
+
+// resetStats resets the statistics of the context on initializing.
+func (ctx *Context) resetStats() {
+	ctx.stats = contextStats{}
+	if StatsAllocations {
+		ctx.stats.mallocs = readMallocs()
+	}
+	aggregateStats.contexts.Add(1)
+}
+
+// recordHandler records a handler executed by the context.
+func (ctx *Context) recordHandler() {
+	ctx.stats.handlersExecuted++
+	aggregateStats.handlersExecuted.Add(1)
+}
+
+// recordRead records the bytes of the request body read through the context.
+func (ctx *Context) recordRead(n int) {
+	if n <= 0 {
+		return
+	}
+	ctx.stats.bytesRead += int64(n)
+	aggregateStats.bytesRead.Add(uint64(n))
+}
+
+// recordWrite records the bytes of the response body written through the context.
+func (ctx *Context) recordWrite(n int) {
+	if n <= 0 {
+		return
+	}
+	ctx.stats.bytesWritten += int64(n)
+	aggregateStats.bytesWritten.Add(uint64(n))
+}
+
+// heapAllocsMetric is the runtime metric of the cumulative count of heap allocations.
+const heapAllocsMetric = "/gc/heap/allocs:objects"
+
+// readMallocs returns the cumulative count of heap allocations of the process.
+func readMallocs() uint64 {
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+
+	return sample[0].Value.Uint64()
+}
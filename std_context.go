@@ -0,0 +1,26 @@
+package simple_context
+
+import (
+	"context"
+)
+
+// StdContext returns the standard context of the request, for the downstream calls made by the
+// handlers. It's derived from the context of the net/http request if the core implementation is
+// backed by net/http, or the background context otherwise.
+func (ctx *Context) StdContext() context.Context {
+	if ctx.stdCtx != nil {
+		return ctx.stdCtx
+	}
+
+	if req := ctx.RawRequest(); req != nil {
+		return req.Context()
+	}
+
+	return context.Background()
+}
+
+// SetStdContext replaces the standard context of the request, for the middleware that derives it
+// with values, deadlines, or cancellation.
+func (ctx *Context) SetStdContext(stdCtx context.Context) {
+	ctx.stdCtx = stdCtx
+}
@@ -0,0 +1,26 @@
+package simple_context
+
+import (
+	"errors"
+)
+
+// ErrResponseComplete is returned by Write and the renderers if the response has been completed
+// and the context is not in the stream mode.
+var ErrResponseComplete = errors.New("response already complete")
+
+// StreamMode enables incremental writes of the response. Without it, the response is completed by
+// the render helpers like JSON and String or by the first Write, and any further writes fail with
+// ErrResponseComplete, so middleware can't append garbage after a single-shot body.
+func (ctx *Context) StreamMode() {
+	ctx.streaming = true
+}
+
+// IsStreaming checks if the context is in the stream mode.
+func (ctx *Context) IsStreaming() bool {
+	return ctx.streaming
+}
+
+// IsComplete checks if the response has been completed by a renderer or a single-shot write.
+func (ctx *Context) IsComplete() bool {
+	return ctx.complete
+}
@@ -0,0 +1,195 @@
+package simple_context
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// TimeStyle is the style of timestamps formatted and parsed by a time policy.
+type TimeStyle int
+
+const (
+	// TimeStyleRFC3339 renders timestamps as RFC 3339 strings with nanoseconds when present.
+	TimeStyleRFC3339 TimeStyle = iota
+	// TimeStyleUnixMilli renders timestamps as the number of milliseconds since the Unix epoch.
+	TimeStyleUnixMilli
+	// TimeStyleLayout renders timestamps with the custom layout of the policy.
+	TimeStyleLayout
+)
+
+// TimePolicy describes how timestamps are rendered and parsed.
+type TimePolicy struct {
+	// Style is the rendering style of timestamps.
+	Style TimeStyle
+	// Layout is the layout used by TimeStyleLayout, in the format of time.Time.Format.
+	Layout string
+	// UTC converts timestamps to UTC before rendering them.
+	UTC bool
+}
+
+// DefaultTimePolicy is the time policy of the Time type in JSON and XML documents, and the default
+// time policy of the contexts. Plain time.Time values in documents are encoded by encoding/json and
+// encoding/xml as RFC 3339 strings regardless of the policy.
+var DefaultTimePolicy = TimePolicy{Style: TimeStyleRFC3339}
+
+// FormatTime formats the time by the policy.
+func (p TimePolicy) FormatTime(t time.Time) string {
+	if p.UTC {
+		t = t.UTC()
+	}
+
+	switch p.Style {
+	case TimeStyleUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case TimeStyleLayout:
+		if p.Layout != "" {
+			return t.Format(p.Layout)
+		}
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
+
+// ParseTime parses the string by the policy. It accepts both the representation of the policy
+// and RFC 3339 strings, so clients that send standard timestamps are always understood.
+func (p TimePolicy) ParseTime(s string) (time.Time, error) {
+	var t time.Time
+	var err error
+
+	switch p.Style {
+	case TimeStyleUnixMilli:
+		var ms int64
+		ms, err = strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			t = time.UnixMilli(ms)
+		}
+	case TimeStyleLayout:
+		if p.Layout != "" {
+			t, err = time.Parse(p.Layout, s)
+		} else {
+			t, err = time.Parse(time.RFC3339Nano, s)
+		}
+	default:
+		t, err = time.Parse(time.RFC3339Nano, s)
+	}
+
+	if err != nil {
+		rfcTime, rfcErr := time.Parse(time.RFC3339Nano, s)
+		if rfcErr != nil {
+			return time.Time{}, errors.New("invalid time: " + s)
+		}
+		t = rfcTime
+	}
+
+	if p.UTC {
+		t = t.UTC()
+	}
+
+	return t, nil
+}
+
+// Time is a time.Time that is rendered and parsed by DefaultTimePolicy in JSON and XML documents.
+type Time struct {
+	time.Time
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if DefaultTimePolicy.Style == TimeStyleUnixMilli {
+		return []byte(DefaultTimePolicy.FormatTime(t.Time)), nil
+	}
+
+	return json.Marshal(DefaultTimePolicy.FormatTime(t.Time))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := DefaultTimePolicy.ParseTime(s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+
+	return nil
+}
+
+// MarshalXML implements xml.Marshaler.
+func (t Time) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(DefaultTimePolicy.FormatTime(t.Time), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (t *Time) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+
+	parsed, err := DefaultTimePolicy.ParseTime(s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (t Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: DefaultTimePolicy.FormatTime(t.Time)}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
+	parsed, err := DefaultTimePolicy.ParseTime(attr.Value)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+
+	return nil
+}
+
+// TimePolicy returns the time policy of the context, which is DefaultTimePolicy unless it's
+// replaced by SetTimePolicy.
+func (ctx *Context) TimePolicy() TimePolicy {
+	if ctx.timePolicy != nil {
+		return *ctx.timePolicy
+	}
+
+	return DefaultTimePolicy
+}
+
+// SetTimePolicy replaces the time policy of the context, which is used by FormatTime, ParseTime,
+// BindQuery, and BindForm only. The renderers like JSON and XML don't apply it, the Time values in
+// the documents are always formatted by DefaultTimePolicy, so a handler that needs the policy of
+// the context in a document formats the timestamps by FormatTime before rendering it.
+func (ctx *Context) SetTimePolicy(policy TimePolicy) {
+	ctx.timePolicy = &policy
+}
+
+// FormatTime formats the time by the time policy of the context.
+func (ctx *Context) FormatTime(t time.Time) string {
+	return ctx.TimePolicy().FormatTime(t)
+}
+
+// ParseTime parses the string by the time policy of the context, for binding timestamps from path
+// parameters, queries, and form values.
+func (ctx *Context) ParseTime(s string) (time.Time, error) {
+	return ctx.TimePolicy().ParseTime(s)
+}
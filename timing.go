@@ -0,0 +1,50 @@
+package simple_context
+
+import (
+	"time"
+
+	"github.com/go-amwk/core"
+)
+
+// HandlerTiming is the execution time of a handler of the chain.
+type HandlerTiming struct {
+	// Index is the position of the handler in the chain.
+	Index int
+	// Name is the function name of the handler.
+	Name string
+	// Start is the time when the handler started.
+	Start time.Time
+	// Duration is the wall time of the handler, including the downstream handlers it called by
+	// Next.
+	Duration time.Duration
+	// Self is the wall time of the handler excluding the downstream handlers.
+	Self time.Duration
+}
+
+// HandlerTimings returns the execution time of the handlers executed by Next, in the order they
+// started. The handlers that are still being executed have zero durations.
+func (ctx *Context) HandlerTimings() []HandlerTiming {
+	return append([]HandlerTiming(nil), ctx.handlerTimings...)
+}
+
+// withHandlerTiming calls the function and records the execution time of the handler.
+func (ctx *Context) withHandlerTiming(handler core.HandlerFunc, f func()) {
+	i := len(ctx.handlerTimings)
+	start := ctx.Now()
+	ctx.handlerTimings = append(ctx.handlerTimings, HandlerTiming{
+		Index: ctx.index,
+		Name:  handlerName(handler),
+		Start: start,
+	})
+
+	parentNested := ctx.nestedTime
+	ctx.nestedTime = 0
+	defer func() {
+		d := ctx.Now().Sub(start)
+		ctx.handlerTimings[i].Duration = d
+		ctx.handlerTimings[i].Self = d - ctx.nestedTime
+		ctx.nestedTime = parentNested + d
+	}()
+
+	f()
+}
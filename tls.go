@@ -0,0 +1,36 @@
+package simple_context
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// tlsRequest is implemented by the core requests that are received over TLS connections.
+type tlsRequest interface {
+	TLS() *tls.ConnectionState
+}
+
+// TLS returns the TLS connection state of the request, or nil if the request is not received over
+// a TLS connection or the core implementation doesn't expose it.
+func (ctx *Context) TLS() *tls.ConnectionState {
+	if req, ok := ctx.Request().(tlsRequest); ok {
+		return req.TLS()
+	}
+
+	if req := ctx.RawRequest(); req != nil {
+		return req.TLS
+	}
+
+	return nil
+}
+
+// ClientCertificate returns the leaf certificate presented by the client in a mutual TLS
+// connection, or nil if the client didn't present one.
+func (ctx *Context) ClientCertificate() *x509.Certificate {
+	state := ctx.TLS()
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	return state.PeerCertificates[0]
+}
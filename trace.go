@@ -0,0 +1,63 @@
+package simple_context
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"runtime/trace"
+
+	"github.com/go-amwk/core"
+)
+
+// startTraceTask starts the execution trace task of the request if the execution tracer is
+// enabled and the task has not been started. It returns a function to end the task, which is a
+// no-op if the task is not started by the call.
+func (ctx *Context) startTraceTask() func() {
+	if ctx.traceCtx != nil || !trace.IsEnabled() {
+		return func() {}
+	}
+
+	traceCtx, task := trace.NewTask(context.Background(), "request")
+	trace.Log(traceCtx, "method", ctx.Method())
+	trace.Log(traceCtx, "path", ctx.Path())
+	ctx.traceCtx = traceCtx
+
+	return func() {
+		task.End()
+		ctx.traceCtx = nil
+	}
+}
+
+// withTraceRegion calls the function in an execution trace region named by the handler if the
+// trace task of the request is started.
+func (ctx *Context) withTraceRegion(handler core.HandlerFunc, f func()) {
+	if ctx.traceCtx == nil {
+		f()
+		return
+	}
+
+	trace.WithRegion(ctx.traceCtx, handlerName(handler), f)
+}
+
+// handlerName returns the name of the handler function.
+func handlerName(handler core.HandlerFunc) string {
+	if handler == nil {
+		return ""
+	}
+
+	fn := handlerFunc(handler)
+	if fn == nil {
+		return "unknown"
+	}
+
+	return fn.Name()
+}
+
+// handlerFunc returns the runtime function of the handler.
+func handlerFunc(handler core.HandlerFunc) *runtime.Func {
+	if handler == nil {
+		return nil
+	}
+
+	return runtime.FuncForPC(reflect.ValueOf(handler).Pointer())
+}
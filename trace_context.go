@@ -0,0 +1,132 @@
+package simple_context
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TraceContext is the W3C Trace Context of a request, carried by the traceparent and tracestate
+// headers.
+type TraceContext struct {
+	// TraceID is the lowercase hex ID of the trace, in 32 characters.
+	TraceID string
+	// ParentID is the lowercase hex ID of the parent span, in 16 characters.
+	ParentID string
+	// Flags is the trace flags, the lowest bit is the sampled flag.
+	Flags byte
+	// State is the vendor-specific trace state in the tracestate header.
+	State string
+}
+
+// Sampled checks if the sampled flag is set.
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&0x01 != 0
+}
+
+// TraceParent returns the traceparent header value of the trace context.
+func (tc TraceContext) TraceParent() string {
+	return "00-" + tc.TraceID + "-" + tc.ParentID + "-" + hex.EncodeToString([]byte{tc.Flags})
+}
+
+// ParseTraceParent parses the traceparent header value. The values of the future versions are
+// parsed by their version 00 prefixes.
+func ParseTraceParent(value string) (TraceContext, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return TraceContext{}, false
+	}
+
+	version, traceID, parentID, flags := value[0:2], value[3:35], value[36:52], value[53:55]
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(value) != 55) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(traceID) || traceID == strings.Repeat("0", 32) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(parentID) || parentID == strings.Repeat("0", 16) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(flags) {
+		return TraceContext{}, false
+	}
+
+	flagBytes, _ := hex.DecodeString(flags)
+	return TraceContext{TraceID: traceID, ParentID: parentID, Flags: flagBytes[0]}, true
+}
+
+// isLowerHex checks if the string has only the lowercase hex digits.
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TraceContext returns the W3C Trace Context of the request parsed from the traceparent and
+// tracestate headers, or false if the request has no valid traceparent header.
+func (ctx *Context) TraceContext() (TraceContext, bool) {
+	tc, ok := ParseTraceParent(ctx.Header("traceparent"))
+	if !ok {
+		return TraceContext{}, false
+	}
+	tc.State = strings.Join(ctx.HeaderValues("tracestate"), ",")
+
+	return tc, true
+}
+
+// SpanID returns the lowercase hex ID of the span of the request in this service, it's generated
+// on the first call.
+func (ctx *Context) SpanID() string {
+	if ctx.spanID == "" {
+		id, err := ctx.RandomBytes(8)
+		if err != nil {
+			return ""
+		}
+		ctx.spanID = hex.EncodeToString(id)
+	}
+
+	return ctx.spanID
+}
+
+// OutgoingTraceContext returns the trace context for the outbound requests made by the handlers,
+// which continues the trace of the request with the span of the request as the parent. A new
+// sampled trace is started if the request has no trace context.
+func (ctx *Context) OutgoingTraceContext() TraceContext {
+	tc, ok := ctx.TraceContext()
+	if !ok {
+		if ctx.traceID == "" {
+			id, err := ctx.RandomBytes(16)
+			if err != nil {
+				return TraceContext{}
+			}
+			ctx.traceID = hex.EncodeToString(id)
+		}
+		tc = TraceContext{TraceID: ctx.traceID, Flags: 0x01}
+	}
+	tc.ParentID = ctx.SpanID()
+
+	return tc
+}
+
+// InjectTraceContext sets the traceparent and tracestate headers of the outbound request to the
+// trace context returned by OutgoingTraceContext.
+func (ctx *Context) InjectTraceContext(header http.Header) {
+	tc := ctx.OutgoingTraceContext()
+	if tc.TraceID == "" || tc.ParentID == "" {
+		return
+	}
+
+	header.Set("traceparent", tc.TraceParent())
+	if tc.State != "" {
+		header.Set("tracestate", tc.State)
+	} else {
+		header.Del("tracestate")
+	}
+}
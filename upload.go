@@ -0,0 +1,134 @@
+package simple_context
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrUploadTypeNotAllowed is returned by ValidateUpload if the sniffed type of the file is not
+	// allowed.
+	ErrUploadTypeNotAllowed = errors.New("upload type not allowed")
+	// ErrUploadExtensionNotAllowed is returned by ValidateUpload if the extension of the file name
+	// is not allowed.
+	ErrUploadExtensionNotAllowed = errors.New("upload extension not allowed")
+)
+
+// uploadRules are the rules of ValidateUpload.
+type uploadRules struct {
+	types      []string
+	extensions []string
+	maxSize    int64
+}
+
+// UploadRule is a rule of ValidateUpload.
+type UploadRule func(rules *uploadRules)
+
+// AllowedTypes allows the files whose sniffed media types are in the types. A type may be a
+// wildcard like "image/*".
+func AllowedTypes(types ...string) UploadRule {
+	return func(rules *uploadRules) {
+		rules.types = append(rules.types, types...)
+	}
+}
+
+// AllowedExtensions allows the files whose names have the extensions, like ".png". The extensions
+// are matched case-insensitively.
+func AllowedExtensions(extensions ...string) UploadRule {
+	return func(rules *uploadRules) {
+		rules.extensions = append(rules.extensions, extensions...)
+	}
+}
+
+// MaxSize allows the files up to the size in bytes.
+func MaxSize(size int64) UploadRule {
+	return func(rules *uploadRules) {
+		rules.maxSize = size
+	}
+}
+
+// ValidateUpload validates the uploaded file by the rules. The type of the file is sniffed from its
+// content by the magic bytes, the Content-Type declared by the client is never trusted.
+func ValidateUpload(fh *multipart.FileHeader, opts ...UploadRule) error {
+	rules := new(uploadRules)
+	for _, opt := range opts {
+		opt(rules)
+	}
+
+	if rules.maxSize > 0 && fh.Size > rules.maxSize {
+		return ErrUploadTooLarge
+	}
+
+	if len(rules.extensions) > 0 {
+		ext := filepath.Ext(fh.Filename)
+		allowed := false
+		for _, e := range rules.extensions {
+			if ext != "" && strings.EqualFold(ext, "."+strings.TrimPrefix(e, ".")) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return ErrUploadExtensionNotAllowed
+		}
+	}
+
+	if len(rules.types) > 0 {
+		mediaType, err := DetectUploadType(fh)
+		if err != nil {
+			return err
+		}
+		allowed := false
+		for _, t := range rules.types {
+			if mediaTypeMatches(t, mediaType) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return ErrUploadTypeNotAllowed
+		}
+	}
+
+	return nil
+}
+
+// DetectUploadType returns the media type of the uploaded file sniffed from the first 512 bytes of
+// its content, without the parameters.
+func DetectUploadType(fh *multipart.FileHeader) (string, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", err
+	}
+
+	return mediaType, nil
+}
+
+// mediaTypeMatches checks if the media type matches the pattern, which may be a wildcard like
+// "image/*".
+func mediaTypeMatches(pattern, mediaType string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
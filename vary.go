@@ -0,0 +1,67 @@
+package simple_context
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AddVary adds the header names to the Vary header of the response. The names are merged with the
+// existing values, including the repeated and the comma-joined ones, and deduplicated
+// case-insensitively, so middleware like compression and i18n can add their names independently.
+// The header is collapsed to "*" if any name is "*".
+func (ctx *Context) AddVary(headers ...string) {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		if name != "*" {
+			name = http.CanonicalHeaderKey(name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, existing := range ctx.responseHeaderValues("Vary") {
+		for _, value := range strings.Split(existing, ",") {
+			add(value)
+		}
+	}
+	for _, header := range headers {
+		for _, value := range strings.Split(header, ",") {
+			add(value)
+		}
+	}
+
+	if seen["*"] {
+		ctx.SetHeader("Vary", "*")
+	} else if len(names) > 0 {
+		ctx.SetHeader("Vary", strings.Join(names, ", "))
+	}
+}
+
+// headerValuesResponse is implemented by the responses that return all values of a header.
+type headerValuesResponse interface {
+	HeaderValues(key string) []string
+}
+
+// responseHeaderValues returns all values of the response header. It falls back to the first value
+// if the core implementation exposes neither all values nor the net/http response writer.
+func (ctx *Context) responseHeaderValues(key string) []string {
+	if res, ok := ctx.Response().(headerValuesResponse); ok {
+		return res.HeaderValues(key)
+	}
+	if w := ctx.RawResponseWriter(); w != nil {
+		return w.Header().Values(key)
+	}
+
+	if value := ctx.GetHeader(key); value != "" {
+		return []string{value}
+	}
+	return nil
+}
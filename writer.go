@@ -0,0 +1,109 @@
+package simple_context
+
+import (
+	"net/http"
+)
+
+// flusher is implemented by the core responses that can flush the buffered data to the client.
+type flusher interface {
+	Flush()
+}
+
+// writeBody writes the data to the response buffer if the response is buffered, or to the
+// response directly. The data written by it is not encoded.
+func (ctx *Context) writeBody(data []byte) (int, error) {
+	if ctx.resBuffer != nil {
+		return ctx.resBuffer.Write(data)
+	}
+
+	// the implicit 200 OK status is sent with the body, only the buffered body keeps the status
+	// replaceable.
+	ctx.statusWritten = true
+	return ctx.Response().Write(data)
+}
+
+// Flush flushes the data encoded by the response encoder, and sends the written data to the
+// client if the core implementation supports it. It does nothing for the buffered responses.
+func (ctx *Context) Flush() error {
+	if f, ok := ctx.encoder.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if ctx.resBuffer != nil {
+		return nil
+	}
+
+	if f, ok := ctx.Response().(flusher); ok {
+		f.Flush()
+	} else if w := ctx.RawResponseWriter(); w != nil {
+		return http.NewResponseController(w).Flush()
+	}
+
+	return nil
+}
+
+// bodyWriter writes the data to the response of the context by writeBody.
+type bodyWriter struct {
+	ctx *Context
+}
+
+// Write writes the data to the response of the context.
+func (w bodyWriter) Write(p []byte) (int, error) {
+	return w.ctx.writeBody(p)
+}
+
+// responseWriter writes to the response of the context directly, bypassing the buffering.
+type responseWriter struct {
+	ctx *Context
+}
+
+// Write writes the data to the response of the context.
+func (w responseWriter) Write(p []byte) (int, error) {
+	return w.ctx.Response().Write(p)
+}
+
+// SynchronizedWriter is a writer of the response body that is safe for concurrent use. All
+// synchronized writers of a context share the same lock, so writes and flushes from multiple
+// goroutines are never interleaved.
+type SynchronizedWriter struct {
+	ctx *Context
+}
+
+// SynchronizedWriter returns a writer of the response body that serializes the writes and flushes
+// from multiple goroutines, for streaming handlers like Server-Sent Events. It enables the stream
+// mode of the context.
+func (ctx *Context) SynchronizedWriter() *SynchronizedWriter {
+	ctx.StreamMode()
+	return &SynchronizedWriter{ctx: ctx}
+}
+
+// Write writes the data to the response body.
+func (w *SynchronizedWriter) Write(p []byte) (int, error) {
+	w.ctx.writeMu.Lock()
+	defer w.ctx.writeMu.Unlock()
+
+	return w.ctx.Write(p)
+}
+
+// Flush sends the written data to the client.
+func (w *SynchronizedWriter) Flush() error {
+	w.ctx.writeMu.Lock()
+	defer w.ctx.writeMu.Unlock()
+
+	return w.ctx.Flush()
+}
+
+// WriteFrame writes the data as a whole and flushes it to the client, so a frame like a Server-Sent
+// Event is delivered without being split or interleaved with other writes.
+func (w *SynchronizedWriter) WriteFrame(p []byte) error {
+	w.ctx.writeMu.Lock()
+	defer w.ctx.writeMu.Unlock()
+
+	if _, err := w.ctx.Write(p); err != nil {
+		return err
+	}
+
+	return w.ctx.Flush()
+}
@@ -0,0 +1,151 @@
+package simple_context
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-amwk/core"
+)
+
+func TestWriteCompletedResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		streaming bool
+		first     func(ctx *Context) error
+		second    func(ctx *Context) error
+		wantErr   error
+		wantBody  string
+	}{
+		{
+			name:     "write after write",
+			first:    func(ctx *Context) error { _, err := ctx.Write([]byte("a")); return err },
+			second:   func(ctx *Context) error { _, err := ctx.Write([]byte("b")); return err },
+			wantErr:  ErrResponseComplete,
+			wantBody: "a",
+		},
+		{
+			name:     "write after render",
+			first:    func(ctx *Context) error { return ctx.String(http.StatusOK, "a") },
+			second:   func(ctx *Context) error { _, err := ctx.Write([]byte("b")); return err },
+			wantErr:  ErrResponseComplete,
+			wantBody: "a",
+		},
+		{
+			name:     "render after write",
+			first:    func(ctx *Context) error { _, err := ctx.Write([]byte("a")); return err },
+			second:   func(ctx *Context) error { return ctx.JSON(http.StatusOK, "b") },
+			wantErr:  ErrResponseComplete,
+			wantBody: "a",
+		},
+		{
+			name:      "render in stream mode",
+			streaming: true,
+			first:     func(ctx *Context) error { return ctx.String(http.StatusOK, "a") },
+			second:    func(ctx *Context) error { return ctx.String(http.StatusOK, "b") },
+			wantErr:   ErrResponseComplete,
+			wantBody:  "a",
+		},
+		{
+			name:      "writes in stream mode",
+			streaming: true,
+			first:     func(ctx *Context) error { _, err := ctx.Write([]byte("a")); return err },
+			second:    func(ctx *Context) error { _, err := ctx.Write([]byte("b")); return err },
+			wantBody:  "ab",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := runTestContext(t, func(c core.Context) {
+				ctx := c.(*Context)
+				if tt.streaming {
+					ctx.StreamMode()
+				}
+				if err := tt.first(ctx); err != nil {
+					t.Fatalf("first write error = %v", err)
+				}
+				if !ctx.IsComplete() && !tt.streaming {
+					t.Error("IsComplete() = false after the first write")
+				}
+				if err := tt.second(ctx); !errors.Is(err, tt.wantErr) {
+					t.Errorf("second write error = %v, want %v", err, tt.wantErr)
+				}
+			})
+
+			if string(res.body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", res.body, tt.wantBody)
+			}
+			if res.header.Get("Content-Type") == mimeJSON+"; charset=utf-8" {
+				t.Error("the rejected render changed the Content-Type header")
+			}
+		})
+	}
+}
+
+func TestSynchronizedWriter(t *testing.T) {
+	const (
+		writers = 8
+		frames  = 50
+	)
+
+	res := runTestContext(t, func(c core.Context) {
+		w := c.(*Context).SynchronizedWriter()
+
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				frame := []byte("data: " + strings.Repeat(string(rune('a'+i)), 16) + "\n\n")
+				for j := 0; j < frames; j++ {
+					if err := w.WriteFrame(frame); err != nil {
+						t.Errorf("WriteFrame() error = %v", err)
+						return
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	chunks := bytes.Split(bytes.TrimSuffix(res.body, []byte("\n\n")), []byte("\n\n"))
+	if len(chunks) != writers*frames {
+		t.Fatalf("frames = %d, want %d", len(chunks), writers*frames)
+	}
+	for _, chunk := range chunks {
+		data, ok := bytes.CutPrefix(chunk, []byte("data: "))
+		if !ok || len(data) != 16 || len(bytes.Trim(data, string(data[:1]))) != 0 {
+			t.Fatalf("frame %q is interleaved", chunk)
+		}
+	}
+	if res.flushes != writers*frames {
+		t.Errorf("flushes = %d, want %d", res.flushes, writers*frames)
+	}
+}
+
+func TestFlushBufferedResponse(t *testing.T) {
+	res := runTestContext(t, func(c core.Context) {
+		ctx := c.(*Context)
+		ctx.StreamMode()
+		if err := ctx.BufferResponse(); err != nil {
+			t.Fatalf("BufferResponse() error = %v", err)
+		}
+		if _, err := ctx.Write([]byte("buffered")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := ctx.Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+	})
+
+	if res.flushes != 0 {
+		t.Errorf("flushes = %d, want no flush of the buffered response", res.flushes)
+	}
+	if string(res.body) != "buffered" {
+		t.Errorf("body = %q, want %q", res.body, "buffered")
+	}
+}